@@ -0,0 +1,207 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that stands still until Advance moves it
+// timers and tickers fire during Advance, in the order of their deadlines, so the goroutines waiting on them see a predictable sequence.
+// A test usually starts the code under test in a goroutine, calls BlockUntil to know it is waiting and then Advance.
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond // broadcast whenever a timer is added or removed, BlockUntil waits on it
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+// NewFake returns a Fake reading now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.newTimer(d, 0)
+}
+
+// NewTicker panics if d is not positive, like time.NewTicker
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.newTimer(d, d)}
+}
+
+// Sleep blocks until another goroutine advances the clock by d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+func (f *Fake) newTimer(d, period time.Duration) *fakeTimer {
+	// like time.Timer the channel holds one value so firing never blocks Advance
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1), period: period}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.schedule(t, d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer and tick whose deadline is reached on the way
+// a ticker fires once for every period it passes, but its channel only holds one tick so unread ticks are dropped like time.Ticker does.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	end := f.now.Add(d)
+	for {
+		next := f.earliest()
+		if next == nil || next.deadline.After(end) {
+			break
+		}
+		f.now = next.deadline
+		select {
+		case next.c <- next.deadline:
+		default: // the previous value was never read
+		}
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			f.unschedule(next)
+		}
+	}
+	f.now = end
+}
+
+// earliest is the waiting timer with the first deadline, f.mu must be held
+func (f *Fake) earliest() *fakeTimer {
+	var first *fakeTimer
+	for _, t := range f.timers {
+		if first == nil || t.deadline.Before(first.deadline) {
+			first = t
+		}
+	}
+	return first
+}
+
+// Waiters is the number of timers and tickers that have not fired or been stopped yet
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil waits until at least n timers and tickers are waiting to fire, Sleep and After count as a timer
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.timers) < n {
+		f.changed.Wait()
+	}
+}
+
+// BlockUntilTicksRead waits until the tick last sent by every running ticker has been received
+// call it between two Advances to be sure the goroutine reading a ticker did not miss a tick
+func (f *Fake) BlockUntilTicksRead() {
+	for {
+		f.mu.Lock()
+		unread := false
+		for _, t := range f.timers {
+			if t.period > 0 && len(t.c) > 0 {
+				unread = true
+			}
+		}
+		f.mu.Unlock()
+		if !unread {
+			return
+		}
+		// nothing is signalled when a channel is read so keep looking until it has been
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// schedule adds t to fire after d, f.mu must be held
+func (f *Fake) schedule(t *fakeTimer, d time.Duration) {
+	t.deadline = f.now.Add(d)
+	if d <= 0 {
+		// an expired timer fires straight away, the same as time.NewTimer(0)
+		select {
+		case t.c <- t.deadline:
+		default:
+		}
+		return
+	}
+	f.timers = append(f.timers, t)
+	f.changed.Broadcast()
+}
+
+// unschedule removes t and reports whether it was still waiting, f.mu must be held
+func (f *Fake) unschedule(t *fakeTimer) bool {
+	for i, w := range f.timers {
+		if w == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			f.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration // 0 for a timer, the interval for a ticker
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.unschedule(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.unschedule(t)
+	t.clock.schedule(t, d)
+	return active
+}
+
+// fakeTicker adapts fakeTimer to the Ticker method set
+type fakeTicker struct {
+	t *fakeTimer
+}
+
+func (k fakeTicker) C() <-chan time.Time {
+	return k.t.c
+}
+
+func (k fakeTicker) Stop() {
+	k.t.Stop()
+}
+
+func (k fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	k.t.clock.mu.Lock()
+	defer k.t.clock.mu.Unlock()
+	k.t.clock.unschedule(k.t)
+	k.t.period = d
+	k.t.clock.schedule(k.t, d)
+}
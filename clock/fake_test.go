@@ -0,0 +1,115 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func fired(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeTimerFiresOnAdvance(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Minute)
+	f.Advance(59 * time.Second)
+	if _, ok := fired(timer.C()); ok {
+		t.Fatal("timer fired before its deadline")
+	}
+	f.Advance(time.Second)
+	if at, ok := fired(timer.C()); !ok || !at.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("timer fired %t at %v; want true at %v", ok, at, epoch.Add(time.Minute))
+	}
+	if !f.Now().Equal(epoch.Add(time.Minute)) {
+		t.Errorf("Now() = %v; want %v", f.Now(), epoch.Add(time.Minute))
+	}
+}
+
+func TestFakeTimerStopAndReset(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	if !timer.Stop() || timer.Stop() {
+		t.Error("Stop() should report true only for the first call")
+	}
+	f.Advance(time.Hour)
+	if _, ok := fired(timer.C()); ok {
+		t.Error("stopped timer fired")
+	}
+	if timer.Reset(time.Second) {
+		t.Error("Reset() of a stopped timer = true; want false")
+	}
+	f.Advance(time.Second)
+	if _, ok := fired(timer.C()); !ok {
+		t.Error("reset timer did not fire")
+	}
+	if f.Waiters() != 0 {
+		t.Errorf("Waiters() = %d after every timer fired; want 0", f.Waiters())
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-f.After(time.Second)
+	}()
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("goroutine waiting on After was not woken by Advance")
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(time.Second)
+	for i := 1; i <= 3; i++ {
+		f.Advance(time.Second)
+		if at, ok := fired(ticker.C()); !ok || !at.Equal(epoch.Add(time.Duration(i)*time.Second)) {
+			t.Errorf("tick %d fired %t at %v", i, ok, at)
+		}
+	}
+	// an unread tick is dropped, only the first of the ticks passed is kept
+	f.Advance(5 * time.Second)
+	if at, _ := fired(ticker.C()); !at.Equal(epoch.Add(4 * time.Second)) {
+		t.Errorf("tick after a long advance at %v; want %v", at, epoch.Add(4*time.Second))
+	}
+	if _, ok := fired(ticker.C()); ok {
+		t.Error("ticker channel holds more than one tick")
+	}
+	ticker.Reset(time.Minute)
+	f.Advance(59 * time.Second)
+	if _, ok := fired(ticker.C()); ok {
+		t.Error("ticker fired before its new interval")
+	}
+	ticker.Stop()
+	f.Advance(time.Hour)
+	if _, ok := fired(ticker.C()); ok || f.Waiters() != 0 {
+		t.Error("stopped ticker fired")
+	}
+}
+
+func TestFakeSleep(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan time.Time)
+	go func() {
+		f.Sleep(time.Hour)
+		done <- f.Now()
+	}()
+	f.BlockUntil(1)
+	f.Advance(30 * time.Minute)
+	f.Advance(30 * time.Minute)
+	if woke := <-done; !woke.Equal(epoch.Add(time.Hour)) {
+		t.Errorf("Sleep woke at %v; want %v", woke, epoch.Add(time.Hour))
+	}
+}
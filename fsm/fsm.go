@@ -0,0 +1,212 @@
+// Package fsm is a finite state machine for the State pattern, see basics.txt.
+// A Definition declares the states, their entry and exit hooks and the transitions between them,
+// New checks it and returns a Machine that starts in the initial state and moves when an event is fired.
+// Every event that is not declared for the current state is an error instead of being silently ignored,
+// and DOT draws the definition with Graphviz so a workflow is documented by the same code that runs it.
+package fsm
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// State declares a state, OnEnter and OnExit are optional.
+// They run when a transition arrives in or leaves the state, a transition from a state back to itself runs neither.
+type State[S, E comparable] struct {
+	ID      S
+	OnEnter func(from S, event E)
+	OnExit  func(to S, event E)
+}
+
+// Transition moves the machine From a state To another when Event is fired and Guard, if any, returns true.
+// A state can have several transitions for the same event, the first whose guard passes is taken.
+// GuardName describes the guard in the DOT output.
+type Transition[S, E comparable] struct {
+	From      S
+	Event     E
+	To        S
+	Guard     func() bool
+	GuardName string
+}
+
+// Definition is everything New needs, the order of Transitions is the order guards are tried in
+type Definition[S, E comparable] struct {
+	Initial     S
+	States      []State[S, E]
+	Transitions []Transition[S, E]
+}
+
+// ErrUnknownState is returned by New when a transition or the initial state uses a state that was not declared
+type ErrUnknownState struct {
+	State any
+}
+
+func (e ErrUnknownState) Error() string {
+	return fmt.Sprint("fsm: undeclared state ", e.State)
+}
+
+// ErrDuplicateState is returned by New when a state is declared twice
+type ErrDuplicateState struct {
+	State any
+}
+
+func (e ErrDuplicateState) Error() string {
+	return fmt.Sprint("fsm: state ", e.State, " declared twice")
+}
+
+// ErrInvalidTransition is returned by Fire when the current state has no transition for the event
+type ErrInvalidTransition struct {
+	State, Event any
+}
+
+func (e ErrInvalidTransition) Error() string {
+	return fmt.Sprint("fsm: no transition from ", e.State, " on ", e.Event)
+}
+
+// ErrGuardRejected is returned by Fire when the current state has transitions for the event but every guard returned false
+type ErrGuardRejected struct {
+	State, Event any
+}
+
+func (e ErrGuardRejected) Error() string {
+	return fmt.Sprint("fsm: no guard allows ", e.Event, " from ", e.State)
+}
+
+type key[S, E comparable] struct {
+	from  S
+	event E
+}
+
+// Machine is the running state machine, it is not safe for concurrent use
+// and a hook must not call Fire because the transition that runs it has not finished.
+type Machine[S, E comparable] struct {
+	def         Definition[S, E]
+	states      map[S]State[S, E]
+	transitions map[key[S, E]][]Transition[S, E]
+	current     S
+}
+
+// New checks def and returns a Machine in def.Initial, the initial state's OnEnter is not run
+func New[S, E comparable](def Definition[S, E]) (*Machine[S, E], error) {
+	m := &Machine[S, E]{
+		def:         def,
+		states:      make(map[S]State[S, E]),
+		transitions: make(map[key[S, E]][]Transition[S, E]),
+		current:     def.Initial,
+	}
+	for _, s := range def.States {
+		if _, ok := m.states[s.ID]; ok {
+			return nil, ErrDuplicateState{s.ID}
+		}
+		m.states[s.ID] = s
+	}
+	if _, ok := m.states[def.Initial]; !ok {
+		return nil, ErrUnknownState{def.Initial}
+	}
+	for _, t := range def.Transitions {
+		for _, s := range []S{t.From, t.To} {
+			if _, ok := m.states[s]; !ok {
+				return nil, ErrUnknownState{s}
+			}
+		}
+		k := key[S, E]{t.From, t.Event}
+		m.transitions[k] = append(m.transitions[k], t)
+	}
+	return m, nil
+}
+
+// MustNew is New for definitions known to be valid, it panics on an error like regexp.MustCompile
+func MustNew[S, E comparable](def Definition[S, E]) *Machine[S, E] {
+	m, err := New(def)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// State is the current state
+func (m *Machine[S, E]) State() S {
+	return m.current
+}
+
+// find returns the transition Fire would take
+func (m *Machine[S, E]) find(event E) (Transition[S, E], error) {
+	candidates, ok := m.transitions[key[S, E]{m.current, event}]
+	if !ok {
+		return Transition[S, E]{}, ErrInvalidTransition{m.current, event}
+	}
+	for _, t := range candidates {
+		if t.Guard == nil || t.Guard() {
+			return t, nil
+		}
+	}
+	return Transition[S, E]{}, ErrGuardRejected{m.current, event}
+}
+
+// Can reports whether Fire(event) would succeed now, it runs the guards but no hooks
+func (m *Machine[S, E]) Can(event E) bool {
+	_, err := m.find(event)
+	return err == nil
+}
+
+// Fire takes the transition for event from the current state, running the old state's OnExit and the new one's OnEnter.
+// It returns ErrInvalidTransition or ErrGuardRejected and stays in the current state when there is none to take.
+func (m *Machine[S, E]) Fire(event E) error {
+	t, err := m.find(event)
+	if err != nil {
+		return err
+	}
+	if t.To == m.current {
+		return nil
+	}
+	if exit := m.states[m.current].OnExit; exit != nil {
+		exit(t.To, event)
+	}
+	from := m.current
+	m.current = t.To
+	if enter := m.states[t.To].OnEnter; enter != nil {
+		enter(from, event)
+	}
+	return nil
+}
+
+// DOT writes the definition as a Graphviz digraph called name, render it with "dot -Tsvg".
+// The initial state is pointed at by an arrow from a dot, states with no way out to another state have a double circle
+// and a guarded transition is labelled with its event and GuardName.
+func (m *Machine[S, E]) DOT(w io.Writer, name string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(name))
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\t__start [shape=point];\n")
+	outgoing := make(map[S]bool)
+	for _, t := range m.def.Transitions {
+		if t.From != t.To {
+			outgoing[t.From] = true
+		}
+	}
+	for _, s := range m.def.States {
+		shape := "circle"
+		if !outgoing[s.ID] {
+			shape = "doublecircle"
+		}
+		fmt.Fprintf(&b, "\t%s [shape=%s];\n", quote(s.ID), shape)
+	}
+	fmt.Fprintf(&b, "\t__start -> %s;\n", quote(m.def.Initial))
+	for _, t := range m.def.Transitions {
+		label := fmt.Sprint(t.Event)
+		if t.GuardName != "" {
+			label += " [" + t.GuardName + "]"
+		}
+		fmt.Fprintf(&b, "\t%s -> %s [label=%s];\n", quote(t.From), quote(t.To), strconv.Quote(label))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quote is the DOT id of a state, fmt.Sprint uses its String method when it has one
+func quote(v any) string {
+	return strconv.Quote(fmt.Sprint(v))
+}
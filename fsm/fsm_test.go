@@ -0,0 +1,128 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// door is opened and closed, it can only be locked while closed and only unlocked with the key
+type door struct {
+	hasKey bool
+	log    []string
+	m      *Machine[string, string]
+}
+
+func newDoor(t *testing.T) *door {
+	t.Helper()
+	d := &door{}
+	var err error
+	d.m, err = New(Definition[string, string]{
+		Initial: "closed",
+		States: []State[string, string]{
+			{ID: "closed"},
+			{ID: "open", OnEnter: func(from, event string) { d.log = append(d.log, "enter open from "+from) }},
+			{ID: "locked",
+				OnEnter: func(from, event string) { d.log = append(d.log, "enter locked on "+event) },
+				OnExit:  func(to, event string) { d.log = append(d.log, "exit locked to "+to) }},
+		},
+		Transitions: []Transition[string, string]{
+			{From: "closed", Event: "open", To: "open"},
+			{From: "open", Event: "close", To: "closed"},
+			{From: "closed", Event: "lock", To: "locked"},
+			{From: "locked", Event: "unlock", To: "closed", Guard: func() bool { return d.hasKey }, GuardName: "has key"},
+			{From: "locked", Event: "knock", To: "locked"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestFireAndHooks(t *testing.T) {
+	d := newDoor(t)
+	for _, event := range []string{"open", "close", "lock", "knock"} {
+		if err := d.m.Fire(event); err != nil {
+			t.Fatalf("Fire(%s) = %v", event, err)
+		}
+	}
+	if d.m.State() != "locked" {
+		t.Errorf("state %s; want locked", d.m.State())
+	}
+	d.hasKey = true
+	if err := d.m.Fire("unlock"); err != nil {
+		t.Fatal(err)
+	}
+	// knock goes from locked back to locked so it runs no hooks
+	want := []string{"enter open from closed", "enter locked on lock", "exit locked to closed"}
+	if strings.Join(d.log, "|") != strings.Join(want, "|") {
+		t.Errorf("hooks ran %q; want %q", d.log, want)
+	}
+}
+
+func TestFireErrors(t *testing.T) {
+	d := newDoor(t)
+	err := d.m.Fire("close")
+	var invalid ErrInvalidTransition
+	if !errors.As(err, &invalid) || invalid.State != "closed" || invalid.Event != "close" {
+		t.Errorf("Fire(close) while closed = %v; want ErrInvalidTransition", err)
+	}
+
+	d.m.Fire("lock")
+	if d.m.Can("unlock") {
+		t.Error("Can(unlock) without the key")
+	}
+	if err := d.m.Fire("unlock"); !errors.As(err, &ErrGuardRejected{}) {
+		t.Errorf("Fire(unlock) without the key = %v; want ErrGuardRejected", err)
+	}
+	if d.m.State() != "locked" {
+		t.Errorf("a rejected event moved the door to %s", d.m.State())
+	}
+}
+
+func TestNewChecksDefinition(t *testing.T) {
+	tests := []struct {
+		name string
+		def  Definition[int, string]
+		want error
+	}{
+		{"undeclared initial", Definition[int, string]{Initial: 9, States: []State[int, string]{{ID: 1}}}, ErrUnknownState{9}},
+		{"duplicate", Definition[int, string]{Initial: 1, States: []State[int, string]{{ID: 1}, {ID: 1}}}, ErrDuplicateState{1}},
+		{"undeclared target", Definition[int, string]{
+			Initial:     1,
+			States:      []State[int, string]{{ID: 1}},
+			Transitions: []Transition[int, string]{{From: 1, Event: "go", To: 2}},
+		}, ErrUnknownState{2}},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.def); err != tt.want {
+			t.Errorf("%s: New = %v; want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	d := newDoor(t)
+	var b strings.Builder
+	if err := d.m.DOT(&b, "door"); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph "door" {
+	rankdir=LR;
+	__start [shape=point];
+	"closed" [shape=circle];
+	"open" [shape=circle];
+	"locked" [shape=circle];
+	__start -> "closed";
+	"closed" -> "open" [label="open"];
+	"open" -> "closed" [label="close"];
+	"closed" -> "locked" [label="lock"];
+	"locked" -> "closed" [label="unlock [has key]"];
+	"locked" -> "locked" [label="knock"];
+}
+`
+	if b.String() != want {
+		t.Errorf("DOT\n%s\nwant\n%s", b.String(), want)
+	}
+}
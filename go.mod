@@ -1,3 +1,3 @@
 module lessons
 
-go 1.15
\ No newline at end of file
+go 1.21
\ No newline at end of file
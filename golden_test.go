@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lessons/registry"
+)
+
+// Golden files record what every registered lesson prints
+// RUN go test -run TestGolden -update to rewrite them after changing a lesson
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+func TestGolden(t *testing.T) {
+	for _, l := range registry.Lessons() {
+		l := l
+		t.Run(l.Name, func(t *testing.T) {
+			if l.Unchecked != "" {
+				t.Skip(l.Unchecked)
+			}
+			out, err := registry.Capture(l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := l.Mask(out)
+
+			golden := filepath.Join("testdata", "golden", l.Name+".golden")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v, run go test -run TestGolden -update to create it", err)
+			}
+			if got != string(want) {
+				t.Errorf("output of %s does not match %s\n--- got ---\n%s\n--- want ---\n%s", l.Name, golden, got, want)
+			}
+		})
+	}
+}
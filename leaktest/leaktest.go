@@ -0,0 +1,119 @@
+// Package leaktest finds goroutines a test started and left running.
+// Check takes a snapshot of the running goroutines when a test starts and another once the test and its cleanups are done,
+// any goroutine in the second that was not in the first has leaked and the test fails with its stack.
+package leaktest
+
+import (
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Timeout is how long Check waits for goroutines to exit, a cancelled goroutine stops asynchronously
+// so it usually needs a moment after the test has returned.
+var Timeout = time.Second
+
+// ignored are the packages whose goroutines belong to the runtime or the test framework,
+// a goroutine created by one of them is never reported.
+var ignored = []string{"runtime.", "testing.", "os/signal."}
+
+// goroutine is one goroutine of a runtime.Stack dump
+type goroutine struct {
+	id    int
+	stack string // the whole entry, starting with its "goroutine 7 [chan receive]:" header
+}
+
+// Check fails t when goroutines started during the test are still running after it.
+// Call it first so its cleanup runs after every other cleanup, like closing a test server.
+func Check(t testing.TB) {
+	t.Helper()
+	before := Snapshot()
+	t.Cleanup(func() {
+		if leaked := Leaked(before, Timeout); len(leaked) > 0 {
+			t.Errorf("leaktest: %d goroutines leaked:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+		}
+	})
+}
+
+// Snapshot is the ids of the goroutines running now, to be passed to Leaked later
+func Snapshot() map[int]bool {
+	ids := make(map[int]bool)
+	for _, g := range goroutines() {
+		ids[g.id] = true
+	}
+	return ids
+}
+
+// Leaked returns the stacks of the goroutines that are not in before, waiting up to timeout for them to exit
+func Leaked(before map[int]bool, timeout time.Duration) []string {
+	deadline := time.Now().Add(timeout)
+	for {
+		var leaked []string
+		for _, g := range goroutines() {
+			if !before[g.id] {
+				leaked = append(leaked, g.stack)
+			}
+		}
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// goroutines parses runtime.Stack for every goroutine but the calling one and those in ignored, ordered by id
+func goroutines() []goroutine {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	// the first entry is always the calling goroutine
+	entries := strings.Split(string(buf), "\n\n")[1:]
+	var all []goroutine
+	for _, stack := range entries {
+		g, ok := parse(stack)
+		if ok && !isIgnored(stack) {
+			all = append(all, g)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].id < all[j].id })
+	return all
+}
+
+// parse reads the id from the "goroutine 7 [chan receive]:" header of an entry
+func parse(stack string) (goroutine, bool) {
+	header, _, _ := strings.Cut(stack, "\n")
+	fields := strings.Fields(header)
+	if len(fields) < 2 || fields[0] != "goroutine" {
+		return goroutine{}, false
+	}
+	id, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return goroutine{}, false
+	}
+	return goroutine{id: id, stack: strings.TrimSpace(stack)}, true
+}
+
+// isIgnored reports whether the goroutine was created by the runtime or the test framework
+func isIgnored(stack string) bool {
+	i := strings.LastIndex(stack, "\ncreated by ")
+	if i < 0 {
+		// only main and the runtime's own goroutines have no creator
+		return true
+	}
+	creator := stack[i+len("\ncreated by "):]
+	for _, prefix := range ignored {
+		if strings.HasPrefix(creator, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package leaktest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder is a testing.TB that keeps its errors and cleanups instead of acting on them
+type recorder struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func blockedForever(release <-chan struct{}) {
+	<-release
+}
+
+func TestCheckReportsLeakedStack(t *testing.T) {
+	Timeout = 10 * time.Millisecond
+	defer func() { Timeout = time.Second }()
+
+	r := &recorder{TB: t}
+	Check(r)
+	release := make(chan struct{})
+	go blockedForever(release)
+	r.finish()
+	close(release)
+
+	if len(r.errors) != 1 {
+		t.Fatalf("got %d errors; want 1 for the leaked goroutine", len(r.errors))
+	}
+	for _, want := range []string{"1 goroutines leaked", "[chan receive]", "leaktest.blockedForever"} {
+		if !strings.Contains(r.errors[0], want) {
+			t.Errorf("error missing %q:\n%s", want, r.errors[0])
+		}
+	}
+}
+
+func TestCheckWaitsForExit(t *testing.T) {
+	r := &recorder{TB: t}
+	Check(r)
+	release := make(chan struct{})
+	go blockedForever(release)
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+	r.finish()
+	if len(r.errors) != 0 {
+		t.Errorf("reported a goroutine that exited in time:\n%s", r.errors[0])
+	}
+}
+
+func TestIgnoresGoroutinesRunningBefore(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	go blockedForever(release)
+
+	r := &recorder{TB: t}
+	Check(r)
+	r.finish()
+	if len(r.errors) != 0 {
+		t.Errorf("reported a goroutine started before Check:\n%s", r.errors[0])
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	tests := []struct {
+		stack string
+		want  bool
+	}{
+		{"goroutine 1 [chan receive]:\nmain.main()\n\t_testmain.go:46 +0x9b", true},
+		{"goroutine 7 [running]:\ntesting.tRunner(0x1, 0x2)\ncreated by testing.(*T).Run in goroutine 1", true},
+		{"goroutine 9 [select]:\nos/signal.loop()\ncreated by os/signal.Notify.func1.1 in goroutine 1", true},
+		{"goroutine 8 [chan receive]:\nlessons.worker()\ncreated by lessons.start in goroutine 7", false},
+	}
+	for _, tt := range tests {
+		if got := isIgnored(tt.stack); got != tt.want {
+			t.Errorf("isIgnored(%q) = %t; want %t", tt.stack, got, tt.want)
+		}
+	}
+}
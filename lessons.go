@@ -17,8 +17,26 @@ package main
 //_ "www-phaeton/v1/modules/logging"
 // git tag -l v1.0.0-b* or git tag -l *beta*
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"lessons/modules/algorithms"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"lessons/registry"
+
+	// each module registers its lessons with the registry as a side effect, see modules/stdimports
+	_ "lessons/modules/algorithms"
+	_ "lessons/modules/concurrency"
+	_ "lessons/modules/conversions"
+	_ "lessons/modules/defering"
+	_ "lessons/modules/functions"
+	_ "lessons/modules/interfaces"
+	_ "lessons/modules/loops"
+	_ "lessons/modules/maps"
+	_ "lessons/modules/structs"
+	_ "lessons/types"
 )
 
 // Intro Go is a general purpose language intended for systems programming.
@@ -35,53 +53,113 @@ import (
 // Inheritance is NOT possible
 // Messaging with Channels
 // Late-binding possible via higher-order-function (function that takes a function as an argument or returns function) and interfaces
+//
+// Lessons are no longer selected by uncommenting calls in main, run them by name instead
+//
+//	go run . list
+//	go run . list --category concurrency
+//	go run . run algorithms.ExampleCommandPattern
+//	go run . run 'functions.ExampleMiddle*'
+//	go run . run --category types
 func main() {
-	//fmt.Println("Hello")
-	//fmt.Println(sample.SillyName())
-	//functions.ExampleProcessSlice()
-	//functions.ExampleFilterSlice()
-	fmt.Println("...")
-	//functions.ExampleMiddleware()
-	//functions.ExampleSearchInt()
-	//functions.ExampleSliceExpand()
-	//functions.ExampleSliceExpand()
-	//functions.ExampleMiddleBool()
-	//structs.MethodMath()
-	//functions.ExampleVariadic()
-	//concurrency.ExampleCurrency()
-	//algorithms.ExampleQueue()
-	//algorithms.ExampleAltQueue()
-	//algorithms.ExampleStack()
-	//algorithms.ExampleConversions()
-	//functions.SortingSlice()
-	//conversions.ExampleConversionCosts()
-	//conversions.ExampleNumericConversions()
-	//functions.ExampleByValueAndReference()
-	//concurrency.ExampleUnbufferedChan()
-	//concurrency.ExampleBufferedChan()
-	//concurrency.ExUnbufferedChan()
-	//concurrency.ExTimeOuts()
-	//concurrency.ExampleSimpleTimer()
-	//concurrency.ExampleSimpleTicker()
-	//concurrency.ExWorkerPool()
-	//types.TypeInformation()
-	//algorithms.ExampleAlgoPatterns()
-	//interfaces.ExampleInterface()
-	//types.ExampleSlice()
-	//types.ExamplePrinting()
-	//types.ExampleMinInts()
-	//types.ExampleBytes()
-	//interfaces.ExampleRot13NoStruct()
-	//types.ExampleEmbed()
-	//interfaces.ExampleAssertion()
-	//interfaces.ExampleErrorPanicRecovery()
-	//structs.ExamplePointerVsValue()
-	//structs.ExampleImplementation()
-	//types.ExampleSliceAddressPtrs()
+	err := run(os.Args[1:], os.Stdout)
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lessons:", err)
+		os.Exit(1)
+	}
+}
+
+const usage = `usage:
+	lessons list [--category name]
+	lessons run <name or glob>...
+	lessons run --category name
+`
+
+var errUsage = errors.New("usage")
+
+// run executes the subcommand in args and writes anything it reports to w
+// lessons themselves still print to stdout
+func run(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "list":
+		return list(args[1:], w)
+	case "run":
+		return runLessons(args[1:], w)
+	}
+	return errUsage
+}
 
-	//algorithms.ExampleRecursiveFunctions()
-	//algorithms.ExamplePermutations()
-	algorithms.ExampleCommandPattern()
+func list(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	category := fs.String("category", "", "only list lessons of this category")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	lessons := registry.Lessons()
+	if *category != "" {
+		lessons = registry.ByCategory(*category)
+		if len(lessons) == 0 {
+			return fmt.Errorf("unknown category %q, available: %v", *category, registry.Categories())
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tCATEGORY\tDESCRIPTION")
+	for _, l := range lessons {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", l.Name, l.Category, l.Description)
+	}
+	return tw.Flush()
+}
+
+func runLessons(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	category := fs.String("category", "", "run every lesson of this category")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	var lessons []registry.Lesson
+	switch {
+	case *category != "" && fs.NArg() > 0:
+		return errUsage
+	case *category != "":
+		lessons = registry.ByCategory(*category)
+		if len(lessons) == 0 {
+			return fmt.Errorf("unknown category %q, available: %v", *category, registry.Categories())
+		}
+	case fs.NArg() == 0:
+		return errUsage
+	}
+	// overlapping patterns like concurrency.* concurrency.ExampleSimpleTimer run each lesson once, in the order it first matched
+	seen := make(map[string]struct{})
+	for _, pattern := range fs.Args() {
+		matched, err := registry.Match(pattern)
+		if err != nil {
+			return err
+		}
+		if len(matched) == 0 {
+			return fmt.Errorf("no lesson matches %q, try lessons list", pattern)
+		}
+		for _, l := range matched {
+			if _, ok := seen[l.Name]; ok {
+				continue
+			}
+			seen[l.Name] = struct{}{}
+			lessons = append(lessons, l)
+		}
+	}
+	for _, l := range lessons {
+		fmt.Fprintf(w, "=== %s\n", l.Name)
+		l.Run()
+	}
+	return nil
 }
 
 func IntMin(a, b int) int {
@@ -4,8 +4,13 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+
+	"lessons/registry"
 )
 
 // RUN Tests with go test -v  -v is verbose mode
@@ -41,3 +46,57 @@ func TestIntMinTableDriven(t *testing.T) {
 		})
 	}
 }
+
+// the command line tests run these instead of real lessons, the golden test skips them
+func init() {
+	for _, name := range []string{"clitest.First", "clitest.Second"} {
+		registry.Register(registry.Lesson{Name: name, Category: "clitest", Description: "runs " + name, Run: func() {},
+			Unchecked: "registered by lessons_test.go for the command line tests"})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string // the output when there is no error
+		wantErr string // a substring of the error, errUsage is matched with errors.Is
+	}{
+		{"no args", nil, "", errUsage.Error()},
+		{"unknown subcommand", []string{"help"}, "", errUsage.Error()},
+		{"run without lessons", []string{"run"}, "", errUsage.Error()},
+		{"category and names", []string{"run", "--category", "clitest", "clitest.First"}, "", errUsage.Error()},
+		{"bad flag", []string{"list", "--verbose"}, "", errUsage.Error()},
+		{"run unknown category", []string{"run", "--category", "nope"}, "", `unknown category "nope"`},
+		{"list unknown category", []string{"list", "--category", "nope"}, "", `unknown category "nope"`},
+		{"no match", []string{"run", "clitest.Third*"}, "", `no lesson matches "clitest.Third*"`},
+		{"bad pattern", []string{"run", "clitest.["}, "", "bad pattern"},
+		{"run by name", []string{"run", "clitest.Second"}, "=== clitest.Second\n", ""},
+		{"run by category", []string{"run", "--category", "clitest"}, "=== clitest.First\n=== clitest.Second\n", ""},
+		{"overlapping patterns", []string{"run", "clitest.Second", "clitest.*"}, "=== clitest.Second\n=== clitest.First\n", ""},
+		{"list", []string{"list", "--category", "clitest"},
+			"NAME            CATEGORY  DESCRIPTION\n" +
+				"clitest.First   clitest   runs clitest.First\n" +
+				"clitest.Second  clitest   runs clitest.Second\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := run(tt.args, &buf)
+			switch {
+			case tt.wantErr == errUsage.Error():
+				if !errors.Is(err, errUsage) {
+					t.Errorf("run(%q) = %v; want errUsage", tt.args, err)
+				}
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run(%q) = %v; want an error containing %q", tt.args, err, tt.wantErr)
+				}
+			case err != nil:
+				t.Errorf("run(%q) = %v", tt.args, err)
+			case buf.String() != tt.want:
+				t.Errorf("run(%q) wrote\n%s\nwant\n%s", tt.args, buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package algorithms
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Combinatorics
+// The iterators follow the same shape as Stack.All, call them with a yield func and return false from it to stop early.
+// To avoid an allocation per result the slice handed to yield is reused, copy it to keep it past the call.
+
+// Permutations yields every ordering of a, n! of them, using Heap's algorithm
+// each permutation differs from the previous one by a single swap. a itself is not modified.
+// https://en.wikipedia.org/wiki/Heap%27s_algorithm
+func Permutations[T any](a []T) func(yield func([]T) bool) {
+	return func(yield func([]T) bool) {
+		p := slices.Clone(a)
+		// c[i] counts the swaps done at position i, it is the loop counter of the recursive version
+		c := make([]int, len(p))
+		if !yield(p) {
+			return
+		}
+		for i := 1; i < len(p); {
+			if c[i] >= i {
+				c[i] = 0
+				i++
+				continue
+			}
+			if i%2 == 0 {
+				p[0], p[i] = p[i], p[0]
+			} else {
+				p[c[i]], p[i] = p[i], p[c[i]]
+			}
+			if !yield(p) {
+				return
+			}
+			c[i]++
+			i = 1
+		}
+	}
+}
+
+// PermutationsFunc calls f with every permutation of a, the callback form of Permutations
+func PermutationsFunc[T any](a []T, f func([]T)) {
+	Permutations(a)(func(p []T) bool {
+		f(p)
+		return true
+	})
+}
+
+// NextPermutation rearranges a into the next permutation in lexicographic order
+// when a is already the last one, i.e. sorted in descending order, it wraps around to the first and returns false
+func NextPermutation[T cmp.Ordered](a []T) bool {
+	// find the rightmost element smaller than its successor, everything after it is descending
+	i := len(a) - 2
+	for i >= 0 && a[i] >= a[i+1] {
+		i--
+	}
+	if i < 0 {
+		slices.Reverse(a)
+		return false
+	}
+	// swap it with the smallest element after it that is still larger, then make the tail ascending
+	j := len(a) - 1
+	for a[j] <= a[i] {
+		j--
+	}
+	a[i], a[j] = a[j], a[i]
+	slices.Reverse(a[i+1:])
+	return true
+}
+
+// UniquePermutations yields the distinct permutations of a in lexicographic order
+// repeated elements are not told apart so "aab" gives 3 permutations instead of 3! = 6
+func UniquePermutations[T cmp.Ordered](a []T) func(yield func([]T) bool) {
+	return func(yield func([]T) bool) {
+		p := slices.Clone(a)
+		slices.Sort(p)
+		for {
+			if !yield(p) {
+				return
+			}
+			if !NextPermutation(p) {
+				return
+			}
+		}
+	}
+}
+
+// Combinations yields every way of choosing k of the indexes 0..n-1, C(n,k) of them, in lexicographic order
+// the indexes are ascending so they can be used to pick elements out of any slice of length n
+func Combinations(n, k int) func(yield func([]int) bool) {
+	return func(yield func([]int) bool) {
+		if k < 0 || k > n {
+			return
+		}
+		idx := make([]int, k)
+		for i := range idx {
+			idx[i] = i
+		}
+		for {
+			if !yield(idx) {
+				return
+			}
+			// find the rightmost index that can still move right, idx[i] can go up to n-k+i
+			i := k - 1
+			for i >= 0 && idx[i] == n-k+i {
+				i--
+			}
+			if i < 0 {
+				return
+			}
+			idx[i]++
+			for j := i + 1; j < k; j++ {
+				idx[j] = idx[j-1] + 1
+			}
+		}
+	}
+}
+
+// PowerSet returns all 2^n subsets of a ordered by size, starting with the empty set
+// unlike the iterators every subset is a new slice
+func PowerSet[T any](a []T) [][]T {
+	subsets := make([][]T, 0, 1<<len(a))
+	for k := 0; k <= len(a); k++ {
+		Combinations(len(a), k)(func(idx []int) bool {
+			subset := make([]T, len(idx))
+			for i, j := range idx {
+				subset[i] = a[j]
+			}
+			subsets = append(subsets, subset)
+			return true
+		})
+	}
+	return subsets
+}
+
+func ExampleCombinatorics() {
+	PermutationsFunc([]int{1, 2, 3}, func(p []int) {
+		fmt.Print(p, " ")
+	})
+	fmt.Println()
+
+	// stop after the first three
+	var n int
+	Permutations([]string{"go", "is", "fun"})(func(p []string) bool {
+		fmt.Println(p)
+		n++
+		return n < 3
+	})
+
+	// the duplicate letters of "moon" only give 4!/2! = 12 different words
+	var words []string
+	UniquePermutations([]rune("moon"))(func(p []rune) bool {
+		words = append(words, string(p))
+		return true
+	})
+	fmt.Println(len(words), words)
+
+	a := []int{1, 3, 2}
+	NextPermutation(a)
+	fmt.Println("after [1 3 2] comes", a)
+
+	team := []string{"ana", "ben", "cho", "dev"}
+	Combinations(len(team), 2)(func(idx []int) bool {
+		fmt.Print(team[idx[0]], "+", team[idx[1]], " ")
+		return true
+	})
+	fmt.Println()
+
+	fmt.Println(PowerSet([]string{"x", "y", "z"}))
+}
@@ -0,0 +1,154 @@
+package algorithms
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func factorial(n int) int {
+	f := 1
+	for i := 2; i <= n; i++ {
+		f *= i
+	}
+	return f
+}
+
+func binomial(n, k int) int {
+	if k < 0 || k > n {
+		return 0
+	}
+	return factorial(n) / (factorial(k) * factorial(n-k))
+}
+
+// collect copies every yielded slice because the iterators reuse theirs
+func collect[T any](seq func(yield func([]T) bool)) [][]T {
+	var all [][]T
+	seq(func(s []T) bool {
+		all = append(all, slices.Clone(s))
+		return true
+	})
+	return all
+}
+
+func distinct[T any](all [][]T) int {
+	seen := make(map[string]struct{})
+	for _, s := range all {
+		seen[fmt.Sprint(s)] = struct{}{}
+	}
+	return len(seen)
+}
+
+func TestPermutationsCount(t *testing.T) {
+	for n := 0; n <= 6; n++ {
+		a := make([]int, n)
+		for i := range a {
+			a[i] = i
+		}
+		all := collect(Permutations(a))
+		if len(all) != factorial(n) || distinct(all) != factorial(n) {
+			t.Errorf("Permutations of %d elements gave %d, %d distinct; want %d", n, len(all), distinct(all), factorial(n))
+		}
+		for i := range a {
+			if a[i] != i {
+				t.Fatalf("Permutations modified its input: %v", a)
+			}
+		}
+	}
+}
+
+func TestPermutationsFuncMatchesRecursive(t *testing.T) {
+	var generic, recursive []string
+	PermutationsFunc([]rune("abcd"), func(p []rune) { generic = append(generic, string(p)) })
+	permutateRune([]rune("abcd"), func(p []rune) { recursive = append(recursive, string(p)) }, 0)
+	slices.Sort(generic)
+	slices.Sort(recursive)
+	if !slices.Equal(generic, recursive) || len(generic) != 24 {
+		t.Errorf("PermutationsFunc = %v; permutateRune = %v", generic, recursive)
+	}
+}
+
+func TestPermutationsStopEarly(t *testing.T) {
+	var n int
+	Permutations([]int{1, 2, 3, 4})(func([]int) bool {
+		n++
+		return n < 5
+	})
+	if n != 5 {
+		t.Errorf("yield called %d times after returning false; want 5", n)
+	}
+}
+
+func TestNextPermutation(t *testing.T) {
+	tests := []struct {
+		in, want []int
+		ok       bool
+	}{
+		{[]int{1, 2, 3}, []int{1, 3, 2}, true},
+		{[]int{1, 3, 2}, []int{2, 1, 3}, true},
+		{[]int{3, 2, 1}, []int{1, 2, 3}, false},
+		{[]int{1, 1, 2}, []int{1, 2, 1}, true},
+		{[]int{}, []int{}, false},
+	}
+	for _, tt := range tests {
+		got := slices.Clone(tt.in)
+		ok := NextPermutation(got)
+		if ok != tt.ok || !slices.Equal(got, tt.want) {
+			t.Errorf("NextPermutation(%v) = %v, %t; want %v, %t", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUniquePermutations(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"abc", factorial(3)},
+		{"aab", factorial(3) / factorial(2)},
+		{"aabb", factorial(4) / (factorial(2) * factorial(2))},
+		{"mississippi", factorial(11) / (factorial(4) * factorial(4) * factorial(2))},
+		{"aaaa", 1},
+	}
+	for _, tt := range tests {
+		all := collect(UniquePermutations([]rune(tt.in)))
+		if len(all) != tt.want || distinct(all) != tt.want {
+			t.Errorf("UniquePermutations(%q) gave %d, %d distinct; want %d", tt.in, len(all), distinct(all), tt.want)
+		}
+		if !slices.IsSortedFunc(all, func(a, b []rune) int { return slices.Compare(a, b) }) {
+			t.Errorf("UniquePermutations(%q) is not in lexicographic order", tt.in)
+		}
+	}
+}
+
+func TestCombinationsCount(t *testing.T) {
+	for n := 0; n <= 7; n++ {
+		for k := -1; k <= n+1; k++ {
+			all := collect(Combinations(n, k))
+			if len(all) != binomial(n, k) || distinct(all) != binomial(n, k) {
+				t.Errorf("Combinations(%d, %d) gave %d, %d distinct; want %d", n, k, len(all), distinct(all), binomial(n, k))
+			}
+			for _, idx := range all {
+				if !slices.IsSorted(idx) || (len(idx) > 0 && (idx[0] < 0 || idx[len(idx)-1] >= n)) {
+					t.Errorf("Combinations(%d, %d) yielded %v", n, k, idx)
+				}
+			}
+		}
+	}
+}
+
+func TestPowerSet(t *testing.T) {
+	for n := 0; n <= 6; n++ {
+		a := make([]int, n)
+		for i := range a {
+			a[i] = i
+		}
+		all := PowerSet(a)
+		if len(all) != 1<<n || distinct(all) != 1<<n {
+			t.Errorf("PowerSet of %d elements gave %d, %d distinct; want %d", n, len(all), distinct(all), 1<<n)
+		}
+		if len(all[0]) != 0 || len(all[len(all)-1]) != n {
+			t.Errorf("PowerSet of %d elements starts with %v and ends with %v", n, all[0], all[len(all)-1])
+		}
+	}
+}
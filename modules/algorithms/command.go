@@ -1,85 +1,254 @@
 package algorithms
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+
+	"lessons/fsm"
 )
 
 // Command Pattern in Go
 // useful when needing to create and execute commands with a task management queue that is separate from the execution of the task itself.
 
+// undo reverses execute so a history can step backwards and forwards through the commands, see history.go
 type command interface {
 	execute() error
+	undo() error
 }
 
 // tomagachi acts like a factory
+// life is the state machine of state.go, a dead tomagachi cannot be fed, played with or poop until it is cleaned
 type tomagachi struct {
 	energy int
 	poop   int
+	life   *fsm.Machine[petState, petEvent]
 }
 
 func newTomagachi() *tomagachi {
-	return &tomagachi{energy: 35, poop: 0}
+	t := &tomagachi{energy: 35, poop: 0}
+	t.life = newPetLife(t)
+	return t
 }
 
 func (t *tomagachi) feedPet(n int) command {
-	return &feed{n, t}
+	return &feed{food: n, t: t}
 }
 func (t *tomagachi) playPet(n int) command {
-	return &play{n, t}
+	return &play{activity: n, t: t}
 }
 func (t *tomagachi) poopPet(n int) command {
-	return &poop{n, t}
+	return &poop{amount: n, t: t}
 }
 
+// errorPolicy decides what executeAll does when a command returns an error
+type errorPolicy int
+
+const (
+	// stopOnError stops at the first failing command, the rest are never run. It is the zero value.
+	stopOnError errorPolicy = iota
+	// continueOnError runs every command and joins all of their errors
+	continueOnError
+	// retryOnError undoes a failing command and executes it again, up to simulate.retries more times, before stopping
+	retryOnError
+	// skipOnError undoes a failing command, marks it skipped and carries on with the next one
+	skipOnError
+)
+
 type simulate struct {
 	commands []command
+	policy   errorPolicy
+	retries  int // only used by retryOnError
+}
+
+// commandStatus records what happened to a single command during executeAll
+type commandStatus int
+
+const (
+	notRun commandStatus = iota
+	succeeded
+	failed
+	skipped
+)
+
+func (s commandStatus) String() string {
+	switch s {
+	case succeeded:
+		return "succeeded"
+	case failed:
+		return "failed"
+	case skipped:
+		return "skipped"
+	}
+	return "not run"
+}
+
+type commandResult struct {
+	command  command
+	status   commandStatus
+	attempts int
+	err      error // the last error returned by the command
 }
 
-func (sim *simulate) executeAll() {
-	var err error
-	for _, commands := range sim.commands {
-		err = commands.execute()
-		// note the implementation of the tomagachi dying will continuously keep being called because the tasks will not stop until the end.
-		if err != nil {
-			fmt.Println("Tomagachi died.")
+// simulationResult has one commandResult per command in the order they were queued
+type simulationResult struct {
+	results []commandResult
+	err     error
+}
+
+// executeAll runs the commands according to sim.policy
+// previously every command kept executing after the tomagachi died, stopOnError is now the default
+func (sim *simulate) executeAll() simulationResult {
+	return sim.executeAllContext(context.Background())
+}
+
+// executeAllContext is executeAll but stops before the next command once ctx is done, the remaining commands are left as notRun
+func (sim *simulate) executeAllContext(ctx context.Context) simulationResult {
+	res := simulationResult{results: make([]commandResult, len(sim.commands))}
+	for i, c := range sim.commands {
+		res.results[i].command = c
+	}
+	var errs []error
+	for i, c := range sim.commands {
+		if err := ctx.Err(); err != nil {
+			res.err = errors.Join(append(errs, err)...)
+			return res
+		}
+		r := &res.results[i]
+		r.attempts, r.err = 1, c.execute()
+		for sim.policy == retryOnError && r.err != nil && r.attempts <= sim.retries {
+			// undo the failed attempt so its effect is not applied twice
+			if err := c.undo(); err != nil {
+				r.err = err
+				break
+			}
+			r.attempts++
+			r.err = c.execute()
+		}
+		if r.err == nil {
+			r.status = succeeded
+			continue
+		}
+
+		switch sim.policy {
+		case continueOnError:
+			r.status = failed
+			errs = append(errs, fmt.Errorf("command %d: %w", i, r.err))
+		case skipOnError:
+			r.status = skipped
+			if err := c.undo(); err != nil {
+				r.status = failed
+				errs = append(errs, fmt.Errorf("command %d: %w", i, err))
+			}
+		default: // stopOnError and retryOnError once the retries are spent
+			r.status = failed
+			res.err = fmt.Errorf("command %d: %w", i, r.err)
+			return res
 		}
 	}
+	res.err = errors.Join(errs...)
+	return res
 }
 
+// every command fires its event on the tomagachi's life before changing it, an event the state does not allow changes nothing.
+// applied remembers whether execute changed the tomagachi so undo never reverses a change that was rejected,
+// undo rewinds history so it is allowed in any state.
 type feed struct {
-	food int
-	t    *tomagachi
+	food    int
+	t       *tomagachi
+	applied bool
 }
 
 func (f *feed) execute() error {
+	if err := f.t.life.Fire(fedEvent); err != nil {
+		return err
+	}
 	f.t.energy += f.food
+	f.applied = true
 	fmt.Println("fed energy:", f.t.energy)
 	return nil
 }
 
+func (f *feed) receiver() any { return f.t }
+
+func (f *feed) undo() error {
+	if !f.applied {
+		return nil
+	}
+	f.t.energy -= f.food
+	f.applied = false
+	fmt.Println("unfed energy:", f.t.energy)
+	return nil
+}
+
 type play struct {
 	activity int
 	t        *tomagachi
+	applied  bool
 }
 
 func (p *play) execute() error {
+	if err := p.t.life.Fire(playedEvent); err != nil {
+		return err
+	}
 	p.t.energy -= p.activity
+	p.applied = true
 	fmt.Println("spent energy:", p.t.energy)
 	return nil
 }
 
+func (p *play) receiver() any { return p.t }
+
+func (p *play) undo() error {
+	if !p.applied {
+		return nil
+	}
+	p.t.energy += p.activity
+	p.applied = false
+	fmt.Println("regained energy:", p.t.energy)
+	return nil
+}
+
+var errTooMuchPoop = errors.New("too much poop.")
+
 type poop struct {
-	amount int
-	t      *tomagachi
+	amount  int
+	t       *tomagachi
+	applied bool
 }
 
+// execute adds the poop first because the guard deciding whether the tomagachi dies looks at the new total
+// the poop that kills it stays applied and is reported as errTooMuchPoop, undo cleans it up
 func (p *poop) execute() error {
 	p.t.poop += p.amount
+	if err := p.t.life.Fire(poopedEvent); err != nil {
+		p.t.poop -= p.amount
+		return err
+	}
+	p.applied = true
 	fmt.Println("total poop:", p.t.poop)
-	if p.t.poop > 75 {
-		return errors.New("too much poop.")
+	if p.t.life.State() == dead {
+		return errTooMuchPoop
+	}
+	return nil
+}
+
+func (p *poop) receiver() any { return p.t }
+
+// undo cleans up after the tomagachi, which revives it if it had died
+// like execute it changes poop before firing so the guard sees the new total, and puts it back if the event is rejected
+func (p *poop) undo() error {
+	if !p.applied {
+		return nil
+	}
+	p.t.poop -= p.amount
+	if err := p.t.life.Fire(cleanedEvent); err != nil {
+		p.t.poop += p.amount
+		return err
 	}
+	p.applied = false
+	fmt.Println("cleaned poop:", p.t.poop)
 	return nil
 }
 
@@ -119,6 +288,46 @@ func ExampleCommandPattern() {
 
 	for i, p := range sims {
 		fmt.Println("pet", i, " did ")
-		p.executeAll()
+		if res := p.executeAll(); res.err != nil {
+			fmt.Println("Tomagachi died.", res.err)
+		}
 	}
+
+	// the same tasks behave differently depending on the error policy
+	policies := []struct {
+		name string
+		sim  simulate
+	}{
+		{"stop on error", simulate{policy: stopOnError}},
+		{"continue on error", simulate{policy: continueOnError}},
+		{"retry twice", simulate{policy: retryOnError, retries: 2}},
+		{"skip on error", simulate{policy: skipOnError}},
+	}
+	for _, p := range policies {
+		fmt.Println("policy:", p.name)
+		pet := newTomagachi()
+		p.sim.commands = []command{pet.poopPet(50), pet.poopPet(30), pet.feedPet(5), pet.poopPet(10)}
+		res := p.sim.executeAll()
+		for i, r := range res.results {
+			fmt.Println(" command", i, r.status, "attempts", r.attempts, "error", r.err)
+		}
+		fmt.Println(" error:", res.err, "| pet energy", pet.energy, "poop", pet.poop)
+	}
+
+	// a history can step back and forward through the commands it executed
+	// and journals them so the session can be rebuilt later
+	var journal strings.Builder
+	pet := newTomagachi()
+	h := newHistory(&journal)
+	h.execute(pet.feedPet(10))
+	h.execute(pet.playPet(4))
+	fmt.Println("execute:", h.execute(pet.poopPet(80)))
+	fmt.Println("undo:", h.undo())
+	fmt.Println("undo:", h.undo())
+	fmt.Println("redo:", h.redo())
+	fmt.Println("pet energy", pet.energy, "poop", pet.poop)
+	fmt.Print("journal:\n", journal.String())
+
+	replayed, err := replayJournal(strings.NewReader(journal.String()))
+	fmt.Println("replayed energy", replayed.energy, "poop", replayed.poop, "error", err)
 }
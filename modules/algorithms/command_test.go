@@ -0,0 +1,198 @@
+package algorithms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"lessons/fsm"
+)
+
+func TestHistoryUndoRedo(t *testing.T) {
+	pet := newTomagachi()
+	h := newHistory(nil)
+	h.execute(pet.feedPet(10))
+	h.execute(pet.playPet(4))
+	if err := h.execute(pet.poopPet(80)); err == nil {
+		t.Error("pooping 80 should kill the tomagachi")
+	}
+
+	if err := h.undo(); err != nil {
+		t.Fatal(err)
+	}
+	if pet.poop != 0 {
+		t.Errorf("poop after undo = %d; want 0", pet.poop)
+	}
+	h.undo()
+	h.undo()
+	if pet.energy != 35 {
+		t.Errorf("energy after undoing everything = %d; want 35", pet.energy)
+	}
+	if err := h.undo(); !errors.Is(err, errNothingToUndo) {
+		t.Errorf("undo on an empty history = %v; want errNothingToUndo", err)
+	}
+
+	h.redo()
+	if pet.energy != 45 {
+		t.Errorf("energy after redo = %d; want 45", pet.energy)
+	}
+	// a new command forgets everything that could have been redone
+	h.execute(pet.feedPet(1))
+	if err := h.redo(); !errors.Is(err, errNothingToRedo) {
+		t.Errorf("redo after execute = %v; want errNothingToRedo", err)
+	}
+}
+
+func TestReplayJournal(t *testing.T) {
+	var journal strings.Builder
+	pet := newTomagachi()
+	h := newHistory(&journal)
+	h.execute(pet.feedPet(3))
+	h.execute(pet.playPet(6))
+	h.execute(pet.poopPet(33))
+	h.undo()
+	h.undo()
+	h.redo()
+	h.execute(pet.poopPet(90))
+
+	replayed, err := replayJournal(strings.NewReader(journal.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.energy != pet.energy || replayed.poop != pet.poop || replayed.life.State() != pet.life.State() {
+		t.Errorf("replayed energy %d poop %d %v; want energy %d poop %d %v",
+			replayed.energy, replayed.poop, replayed.life.State(), pet.energy, pet.poop, pet.life.State())
+	}
+}
+
+func TestReplayJournalMalformed(t *testing.T) {
+	var tests = []string{
+		`{"action":"undo"}`,
+		`{"action":"redo"}`,
+		`{"action":"execute","command":"bathe","amount":1}`,
+		`{"action":"jump"}`,
+		`{"action":`,
+	}
+	for _, journal := range tests {
+		if _, err := replayJournal(strings.NewReader(journal)); err == nil {
+			t.Errorf("replayJournal(%s) should fail", journal)
+		}
+	}
+}
+
+// flaky fails the first failures executions, undo counts how often it was reversed
+type flaky struct {
+	failures int
+	executed int
+	undone   int
+}
+
+func (f *flaky) execute() error {
+	f.executed++
+	if f.executed <= f.failures {
+		return errors.New("transient failure")
+	}
+	return nil
+}
+
+func (f *flaky) undo() error {
+	f.undone++
+	return nil
+}
+
+func TestExecuteAllPolicies(t *testing.T) {
+	var tests = []struct {
+		name     string
+		sim      simulate
+		failures int
+		want     []commandStatus
+		wantErr  bool
+	}{
+		{"stop", simulate{policy: stopOnError}, 1, []commandStatus{succeeded, failed, notRun}, true},
+		{"continue", simulate{policy: continueOnError}, 1, []commandStatus{succeeded, failed, succeeded}, true},
+		{"retry succeeds", simulate{policy: retryOnError, retries: 2}, 2, []commandStatus{succeeded, succeeded, succeeded}, false},
+		{"retry exhausted", simulate{policy: retryOnError, retries: 2}, 3, []commandStatus{succeeded, failed, notRun}, true},
+		{"skip", simulate{policy: skipOnError}, 1, []commandStatus{succeeded, skipped, succeeded}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middle := &flaky{failures: tt.failures}
+			tt.sim.commands = []command{&flaky{}, middle, &flaky{}}
+			res := tt.sim.executeAll()
+			if (res.err != nil) != tt.wantErr {
+				t.Errorf("err = %v; want error %v", res.err, tt.wantErr)
+			}
+			for i, r := range res.results {
+				if r.status != tt.want[i] {
+					t.Errorf("command %d status %v; want %v", i, r.status, tt.want[i])
+				}
+			}
+			// every failed attempt that was retried or skipped has to be undone
+			if tt.sim.policy == retryOnError && middle.undone != middle.executed-1 {
+				t.Errorf("retry executed %d times but undid %d", middle.executed, middle.undone)
+			}
+			if tt.sim.policy == skipOnError && middle.undone != 1 {
+				t.Errorf("skip undid %d times; want 1", middle.undone)
+			}
+		})
+	}
+}
+
+func TestExecuteAllJoinsErrors(t *testing.T) {
+	first, second := &flaky{failures: 1}, &flaky{failures: 1}
+	sim := simulate{commands: []command{first, second}, policy: continueOnError}
+	res := sim.executeAll()
+	if got := strings.Count(res.err.Error(), "transient failure"); got != 2 {
+		t.Errorf("joined error has %d failures; want 2: %v", got, res.err)
+	}
+}
+
+func TestDeadTomagachiRejectsCommands(t *testing.T) {
+	pet := newTomagachi()
+	kill := pet.poopPet(80)
+	if err := kill.execute(); !errors.Is(err, errTooMuchPoop) || pet.life.State() != dead {
+		t.Fatalf("poop 80 = %v in state %v; want errTooMuchPoop and dead", err, pet.life.State())
+	}
+	feed := pet.feedPet(10)
+	if err := feed.execute(); !errors.As(err, &fsm.ErrInvalidTransition{}) {
+		t.Errorf("feeding a dead tomagachi = %v; want fsm.ErrInvalidTransition", err)
+	}
+	// undoing a rejected command must not reverse a change that never happened
+	feed.undo()
+	if pet.energy != 35 {
+		t.Errorf("energy %d after a rejected feed and its undo; want 35", pet.energy)
+	}
+	if err := kill.undo(); err != nil || pet.life.State() != alive || pet.poop != 0 {
+		t.Errorf("cleaning up = %v, state %v, poop %d; want the tomagachi alive with no poop", err, pet.life.State(), pet.poop)
+	}
+}
+
+func TestUndoPoopWhileDead(t *testing.T) {
+	pet := newTomagachi()
+	h := newHistory(nil)
+	h.execute(pet.poopPet(80))
+	if err := h.undo(); err != nil || pet.life.State() != alive || pet.poop != 0 {
+		t.Fatalf("undo = %v, state %v, poop %d; want the tomagachi revived with no poop", err, pet.life.State(), pet.poop)
+	}
+
+	// a life that cannot be cleaned once dead rejects the undo, which must leave both the pet and the history as they were
+	pet = newTomagachi()
+	pet.life = fsm.MustNew(fsm.Definition[petState, petEvent]{
+		Initial:     alive,
+		States:      []fsm.State[petState, petEvent]{{ID: alive}, {ID: dead}},
+		Transitions: []fsm.Transition[petState, petEvent]{{From: alive, Event: poopedEvent, To: dead}},
+	})
+	h = newHistory(nil)
+	h.execute(pet.poopPet(80))
+	for i := 0; i < 2; i++ {
+		if err := h.undo(); !errors.As(err, &fsm.ErrInvalidTransition{}) {
+			t.Errorf("undo %d = %v; want fsm.ErrInvalidTransition", i+1, err)
+		}
+		if pet.poop != 80 || pet.life.State() != dead {
+			t.Errorf("undo %d left poop %d in state %v; want 80 and dead", i+1, pet.poop, pet.life.State())
+		}
+	}
+	if err := h.redo(); !errors.Is(err, errNothingToRedo) {
+		t.Errorf("redo after rejected undos = %v; want errNothingToRedo", err)
+	}
+}
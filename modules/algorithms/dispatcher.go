@@ -0,0 +1,120 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// targeted is implemented by commands that act on a receiver, e.g. feed, play and poop act on a *tomagachi
+// commands without a receiver are independent of each other
+type targeted interface {
+	receiver() any
+}
+
+// receiverOf is what the dispatcher orders commands by
+func receiverOf(c command) any {
+	if t, ok := c.(targeted); ok {
+		return t.receiver()
+	}
+	return c
+}
+
+// groupKey is the map key of receiver, a receiver that cannot be compared like a struct holding a slice
+// would make the map panic so it gets a key of its own and its commands run independently of the others
+func groupKey(receiver any) any {
+	if receiver != nil && !reflect.ValueOf(receiver).Comparable() {
+		return &receiver
+	}
+	return receiver
+}
+
+// dispatcher executes commands on a pool of workers
+// commands for the same receiver run one after the other in the order they were given,
+// commands for different receivers run in parallel. Sharing the receiver's state is therefore never racy.
+type dispatcher struct {
+	workers int
+	policy  errorPolicy
+	retries int
+}
+
+// receiverReport is the final outcome for every command of one receiver
+type receiverReport struct {
+	receiver any
+	simulationResult
+}
+
+func newDispatcher(workers int, policy errorPolicy, retries int) *dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	return &dispatcher{workers: workers, policy: policy, retries: retries}
+}
+
+// dispatch groups the commands by receiver and executes each group as a simulate on the worker pool
+// the reports are in the order each receiver first appeared in commands
+// once ctx is done no further command starts, the commands that did not run are reported as notRun
+func (d *dispatcher) dispatch(ctx context.Context, commands []command) []receiverReport {
+	var (
+		receivers []any
+		groups    []*simulate
+		index     = make(map[any]int)
+	)
+	for _, c := range commands {
+		receiver := receiverOf(c)
+		key := groupKey(receiver)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			receivers = append(receivers, receiver)
+			groups = append(groups, &simulate{policy: d.policy, retries: d.retries})
+		}
+		groups[i].commands = append(groups[i].commands, c)
+	}
+
+	reports := make([]receiverReport, len(groups))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < d.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// each index is owned by a single worker so writing reports[i] needs no lock
+			for i := range jobs {
+				reports[i] = receiverReport{receivers[i], groups[i].executeAllContext(ctx)}
+			}
+		}()
+	}
+	// every group is handed out even after cancellation so its report lists its commands as notRun
+	for i := range groups {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return reports
+}
+
+func ExampleCommandDispatcher() {
+	pets := []*tomagachi{newTomagachi(), newTomagachi(), newTomagachi()}
+	var tasks []command
+	for round := 0; round < 3; round++ {
+		for _, pet := range pets {
+			tasks = append(tasks, pet.feedPet(5), pet.playPet(10), pet.poopPet(30))
+		}
+	}
+
+	// each pet keeps the order of its own tasks while the pets are looked after in parallel
+	d := newDispatcher(len(pets), stopOnError, 0)
+	for i, report := range d.dispatch(context.Background(), tasks) {
+		pet := report.receiver.(*tomagachi)
+		var ran int
+		for _, r := range report.results {
+			if r.status != notRun {
+				ran++
+			}
+		}
+		fmt.Println("pet", i, "ran", ran, "of", len(report.results), "commands, energy", pet.energy, "poop", pet.poop, "error", report.err)
+	}
+}
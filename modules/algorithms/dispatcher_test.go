@@ -0,0 +1,164 @@
+package algorithms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder appends its sequence number to the log of its receiver
+type recorder struct {
+	key  *[]int
+	seq  int
+	wait func() error
+}
+
+func (r *recorder) execute() error {
+	if r.wait != nil {
+		if err := r.wait(); err != nil {
+			return err
+		}
+	}
+	*r.key = append(*r.key, r.seq)
+	return nil
+}
+
+func (r *recorder) undo() error   { return nil }
+func (r *recorder) receiver() any { return r.key }
+
+func TestDispatchKeepsReceiverOrder(t *testing.T) {
+	const receivers, perReceiver = 5, 200
+	logs := make([][]int, receivers)
+	var commands []command
+	for seq := 0; seq < perReceiver; seq++ {
+		for r := range logs {
+			commands = append(commands, &recorder{key: &logs[r], seq: seq})
+		}
+	}
+
+	reports := newDispatcher(3, stopOnError, 0).dispatch(context.Background(), commands)
+	if len(reports) != receivers {
+		t.Fatalf("got %d reports; want %d", len(reports), receivers)
+	}
+	for r, log := range logs {
+		if len(log) != perReceiver {
+			t.Fatalf("receiver %d ran %d commands; want %d", r, len(log), perReceiver)
+		}
+		for i, seq := range log {
+			if seq != i {
+				t.Fatalf("receiver %d ran command %d at position %d", r, seq, i)
+			}
+		}
+	}
+}
+
+func TestDispatchRunsReceiversInParallel(t *testing.T) {
+	// both first commands have to be running at the same time to get through the barrier
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	wait := func() error {
+		barrier.Done()
+		done := make(chan struct{})
+		go func() {
+			barrier.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(2 * time.Second):
+			return errors.New("receivers were not run in parallel")
+		}
+	}
+	var a, b []int
+	commands := []command{&recorder{key: &a, wait: wait}, &recorder{key: &b, wait: wait}}
+	for _, report := range newDispatcher(2, stopOnError, 0).dispatch(context.Background(), commands) {
+		if report.err != nil {
+			t.Error(report.err)
+		}
+	}
+}
+
+func TestDispatchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var log []int
+	commands := []command{
+		&recorder{key: &log, seq: 0},
+		&recorder{key: &log, seq: 1, wait: func() error { cancel(); return nil }},
+		&recorder{key: &log, seq: 2},
+	}
+	reports := newDispatcher(1, stopOnError, 0).dispatch(ctx, commands)
+	report := reports[0]
+	if !errors.Is(report.err, context.Canceled) {
+		t.Errorf("err = %v; want context.Canceled", report.err)
+	}
+	want := []commandStatus{succeeded, succeeded, notRun}
+	for i, r := range report.results {
+		if r.status != want[i] {
+			t.Errorf("command %d status %v; want %v", i, r.status, want[i])
+		}
+	}
+}
+
+func TestDispatchTomagachis(t *testing.T) {
+	pets := []*tomagachi{newTomagachi(), newTomagachi()}
+	var commands []command
+	for i := 0; i < 3; i++ {
+		for _, pet := range pets {
+			commands = append(commands, pet.poopPet(30))
+		}
+	}
+	for _, report := range newDispatcher(2, stopOnError, 0).dispatch(context.Background(), commands) {
+		// the third poop kills each pet, nothing is left to stop
+		if report.err == nil || report.receiver.(*tomagachi).poop != 90 {
+			t.Errorf("report %+v", report)
+		}
+	}
+}
+
+// batch is a command passed by value, its slice makes it impossible to compare
+type batch struct {
+	items []int
+	sum   *int
+}
+
+func (b batch) execute() error {
+	for _, i := range b.items {
+		*b.sum += i
+	}
+	return nil
+}
+
+func (b batch) undo() error { return nil }
+
+// tagged acts on a receiver that cannot be compared either
+type tagged struct {
+	tags []string
+}
+
+func (t tagged) execute() error { return nil }
+func (t tagged) undo() error    { return nil }
+func (t tagged) receiver() any  { return t.tags }
+
+func TestDispatchValueCommands(t *testing.T) {
+	var a, b int
+	commands := []command{batch{[]int{1, 2}, &a}, batch{[]int{3}, &b}, tagged{[]string{"x"}}}
+	reports := newDispatcher(2, stopOnError, 0).dispatch(context.Background(), commands)
+	// a receiver that cannot be a map key gets a report of its own, in the order of the commands
+	if len(reports) != 3 {
+		t.Fatalf("got %d reports; want 3", len(reports))
+	}
+	for i, report := range reports {
+		if report.err != nil || len(report.results) != 1 || report.results[0].status != succeeded {
+			t.Errorf("report %d %+v; want one succeeded command", i, report)
+		}
+	}
+	if _, ok := reports[2].receiver.([]string); !ok {
+		t.Errorf("receiver %v; want the tags", reports[2].receiver)
+	}
+	if a != 3 || b != 3 {
+		t.Errorf("sums %d and %d; want 3 and 3", a, b)
+	}
+}
@@ -3,7 +3,6 @@ package algorithms
 import (
 	"fmt"
 	"net/http"
-	"net/http/httptest"
 )
 
 // Factory pattern is a creational design pattern that provides an interface for creating objects into a superclass by allowing subclasses to alter the type of objects that are created
@@ -74,14 +73,8 @@ func newHTTPClient() doer {
 	return &http.Client{}
 }
 
-type mockClient struct{}
-
-func (*mockClient) Do(req *http.Request) (*http.Response, error) {
-	res := httptest.NewRecorder()
-	return res.Result(), nil
-}
-
 // newMockClient lets you test code without actually making external HTTP Calls
+// the mock is programmed with expectations, see mock_doer.go
 func newMockClient() doer {
 	return &mockClient{}
 }
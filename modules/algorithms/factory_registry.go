@@ -0,0 +1,254 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Abstract factory registry
+// The factories in factory.go are hard-coded, a registry lets concrete constructors plug themselves in under a kind
+// so the implementation can be picked from configuration e.g. {"kind": "mock"} in tests and {"kind": "http"} in production.
+// Each kind declares a schema so a bad configuration is rejected before the constructor runs.
+
+// fieldType is the type a config value must have
+type fieldType int
+
+const (
+	stringField fieldType = iota
+	intField
+	durationField // a time.Duration or a string parsed by time.ParseDuration
+)
+
+func (t fieldType) String() string {
+	switch t {
+	case intField:
+		return "int"
+	case durationField:
+		return "duration"
+	}
+	return "string"
+}
+
+// configField describes one key of a product's configuration
+type configField struct {
+	name     string
+	typ      fieldType
+	required bool
+	fallback any // used when the key is missing and not required
+}
+
+type configSchema []configField
+
+// factoryConfig is the configuration given to Build, typically decoded from JSON
+type factoryConfig map[string]any
+
+func (c factoryConfig) string(key string) string {
+	s, _ := c[key].(string)
+	return s
+}
+
+func (c factoryConfig) int(key string) int {
+	i, _ := c[key].(int)
+	return i
+}
+
+func (c factoryConfig) duration(key string) time.Duration {
+	d, _ := c[key].(time.Duration)
+	return d
+}
+
+var errUnknownKind = errors.New("unknown kind")
+
+// validate checks cfg against the schema and returns a copy with fallbacks applied and values converted to their field type
+// JSON decodes every number as float64 so whole floats are accepted for int fields
+func (s configSchema) validate(cfg factoryConfig) (factoryConfig, error) {
+	out := make(factoryConfig, len(s))
+	known := make(map[string]struct{}, len(s))
+	var errs []error
+	for _, f := range s {
+		known[f.name] = struct{}{}
+		v, ok := cfg[f.name]
+		if !ok {
+			if f.required {
+				errs = append(errs, fmt.Errorf("missing required field %q", f.name))
+				continue
+			}
+			v = f.fallback
+		}
+		converted, err := convertField(f.typ, v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field %q: %w", f.name, err))
+			continue
+		}
+		out[f.name] = converted
+	}
+	var unknown []string
+	for key := range cfg {
+		if _, ok := known[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown) // map order is random, keep the error message stable
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("unknown field %q", key))
+	}
+	return out, errors.Join(errs...)
+}
+
+func convertField(typ fieldType, v any) (any, error) {
+	switch typ {
+	case stringField:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case intField:
+		switch n := v.(type) {
+		case int:
+			return n, nil
+		case float64:
+			if n == float64(int(n)) {
+				return int(n), nil
+			}
+		}
+	case durationField:
+		switch d := v.(type) {
+		case time.Duration:
+			return d, nil
+		case string:
+			return time.ParseDuration(d)
+		}
+	}
+	return nil, fmt.Errorf("want %v, got %T", typ, v)
+}
+
+type product[T any] struct {
+	schema configSchema
+	build  func(factoryConfig) (T, error)
+}
+
+// factoryRegistry builds products of interface T by kind
+// the zero value is empty and ready to register products
+type factoryRegistry[T any] struct {
+	products map[string]product[T]
+}
+
+// register plugs in a constructor, registering a kind twice is an error like registry.Register
+func (f *factoryRegistry[T]) register(kind string, schema configSchema, build func(factoryConfig) (T, error)) error {
+	if f.products == nil {
+		f.products = make(map[string]product[T])
+	}
+	if _, dup := f.products[kind]; dup {
+		return fmt.Errorf("kind %q is already registered", kind)
+	}
+	f.products[kind] = product[T]{schema, build}
+	return nil
+}
+
+// kinds returns the registered kinds sorted
+func (f *factoryRegistry[T]) kinds() []string {
+	kinds := make([]string, 0, len(f.products))
+	for k := range f.products {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Build validates config against the kind's schema and constructs the product
+func (f *factoryRegistry[T]) Build(kind string, config factoryConfig) (T, error) {
+	var zero T
+	p, ok := f.products[kind]
+	if !ok {
+		return zero, fmt.Errorf("%w %q, available kinds: %v", errUnknownKind, kind, f.kinds())
+	}
+	cfg, err := p.schema.validate(config)
+	if err != nil {
+		return zero, fmt.Errorf("invalid %s config: %w", kind, err)
+	}
+	return p.build(cfg)
+}
+
+// animalMaker is the abstract factory animalFactory implements, it creates a matching animal and house
+type animalMaker interface {
+	newAnimal(age int) animal
+	newHouse(squareFeet int) animalHouse
+}
+
+var (
+	greeters factoryRegistry[greeter]
+	animals  factoryRegistry[animalMaker]
+	doers    factoryRegistry[doer]
+)
+
+// mustRegister is only used during package initialisation where a duplicate kind is a programmer error
+func mustRegister(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func init() {
+	mustRegister(greeters.register("person",
+		configSchema{{name: "name", typ: stringField, required: true}, {name: "age", typ: intField, fallback: 0}},
+		func(c factoryConfig) (greeter, error) {
+			if c.int("age") < 0 {
+				return nil, fmt.Errorf("age must not be negative, got %d", c.int("age"))
+			}
+			return NewGreeter(c.string("name"), c.int("age")), nil
+		}))
+	mustRegister(greeters.register("baby",
+		configSchema{{name: "name", typ: stringField, required: true}},
+		func(c factoryConfig) (greeter, error) {
+			p := newPersonFactory(1)(c.string("name"))
+			return &p, nil
+		}))
+
+	mustRegister(animals.register("dog",
+		configSchema{{name: "breed", typ: stringField, fallback: "water"}},
+		func(c factoryConfig) (animalMaker, error) {
+			return animalFactory{c.string("breed"), "kennel"}, nil
+		}))
+	mustRegister(animals.register("cat",
+		configSchema{{name: "breed", typ: stringField, fallback: "siamese"}},
+		func(c factoryConfig) (animalMaker, error) {
+			return animalFactory{c.string("breed"), "house"}, nil
+		}))
+
+	mustRegister(doers.register("http",
+		configSchema{{name: "timeout", typ: durationField, fallback: 30 * time.Second}},
+		func(c factoryConfig) (doer, error) {
+			client := newHTTPClient().(*http.Client)
+			client.Timeout = c.duration("timeout")
+			return client, nil
+		}))
+	mustRegister(doers.register("mock", nil, func(factoryConfig) (doer, error) {
+		return newMockClient(), nil
+	}))
+}
+
+func ExampleFactoryRegistry() {
+	g, err := greeters.Build("person", factoryConfig{"name": "Akira", "age": 33.0})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	g.Greet()
+	fmt.Println()
+
+	cats, _ := animals.Build("cat", nil)
+	fmt.Println(cats.newAnimal(2), cats.newHouse(1300))
+
+	// the implementation of doer is picked from configuration
+	for _, kind := range []string{"mock", "http"} {
+		d, _ := doers.Build(kind, factoryConfig{})
+		fmt.Printf("%s builds %T\n", kind, d)
+	}
+
+	_, err = doers.Build("grpc", nil)
+	fmt.Println(err)
+	_, err = greeters.Build("person", factoryConfig{"age": "old", "height": 180})
+	fmt.Println(err)
+}
@@ -0,0 +1,73 @@
+package algorithms
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFactoryBuildFromJSON(t *testing.T) {
+	var cfg struct {
+		Kind   string        `json:"kind"`
+		Config factoryConfig `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(`{"kind": "http", "config": {"timeout": "2s"}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	d, err := doers.Build(cfg.Kind, cfg.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, ok := d.(*http.Client)
+	if !ok || client.Timeout != 2*time.Second {
+		t.Errorf("Build returned %#v; want *http.Client with a 2s timeout", d)
+	}
+
+	g, err := greeters.Build("person", factoryConfig{"name": "Lola", "age": 16.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := g.(*person); p.Name != "Lola" || p.Age != 16 {
+		t.Errorf("person = %+v; want Lola 16", *p)
+	}
+}
+
+func TestFactoryBuildErrors(t *testing.T) {
+	var tests = []struct {
+		name   string
+		build  func() error
+		wantIn string
+	}{
+		{"unknown kind", func() error { _, err := animals.Build("fish", nil); return err }, "available kinds: [cat dog]"},
+		{"missing field", func() error { _, err := greeters.Build("baby", nil); return err }, `missing required field "name"`},
+		{"wrong type", func() error { _, err := greeters.Build("person", factoryConfig{"name": "A", "age": 1.5}); return err }, `field "age": want int`},
+		{"unknown field", func() error { _, err := doers.Build("mock", factoryConfig{"retries": 3}); return err }, `unknown field "retries"`},
+		{"bad duration", func() error { _, err := doers.Build("http", factoryConfig{"timeout": "soon"}); return err }, `field "timeout"`},
+		{"constructor rejects", func() error { _, err := greeters.Build("person", factoryConfig{"name": "A", "age": -1}); return err }, "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.build()
+			if err == nil || !strings.Contains(err.Error(), tt.wantIn) {
+				t.Errorf("err = %v; want it to contain %q", err, tt.wantIn)
+			}
+		})
+	}
+	if _, err := doers.Build("grpc", nil); !errors.Is(err, errUnknownKind) {
+		t.Errorf("err = %v; want errUnknownKind", err)
+	}
+}
+
+func TestFactoryRegisterDuplicate(t *testing.T) {
+	var f factoryRegistry[greeter]
+	build := func(factoryConfig) (greeter, error) { return &person{}, nil }
+	if err := f.register("person", nil, build); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.register("person", nil, build); err == nil {
+		t.Error("registering a kind twice should fail")
+	}
+}
@@ -1,7 +1,11 @@
 package algorithms
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"time"
@@ -27,50 +31,87 @@ type house struct {
 	livingRoom material
 }
 
-type houseOptions func(*house)
+// houseOptions return an error so an option can reject bad input instead of silently building a broken house
+type houseOptions func(*house) error
 
-func newHouse(opts ...houseOptions) *house {
+// newHouse applies every option, even after one fails, so all of the problems are reported together
+func newHouse(opts ...houseOptions) (*house, error) {
 	var (
 		k = material{name: "steel", cost: 1000, size: 300}
 		p = material{name: "plaster", cost: 1000, size: 300}
 		l = material{name: "carpet", cost: 1000, size: 300}
 	)
 	h := &house{kitchen: k, pool: p, livingRoom: l}
+	var errs []error
 	for _, opt := range opts {
-		opt(h)
+		if err := opt(h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
 	}
-	return h
+	return h, nil
 }
 
 func graniteKitchen() houseOptions {
-	return func(h *house) {
+	return func(h *house) error {
 		h.kitchen.name = "granite"
+		return nil
 	}
 }
 func tilePool() houseOptions {
-	return func(h *house) {
-		h.kitchen.name = "tile"
+	return func(h *house) error {
+		h.pool.name = "tile"
+		return nil
 	}
 }
 func hardwoodRoom(size int) houseOptions {
-	return func(h *house) {
+	return func(h *house) error {
+		if size <= 0 {
+			return fmt.Errorf("hardwoodRoom: size must be positive, got %d", size)
+		}
 		h.livingRoom.name = "hard wood"
 		h.livingRoom.size = size
+		return nil
 	}
 }
 
+// cost totals the cost of every room's material by its size
+func (h *house) cost() int {
+	var total int
+	for _, m := range []material{h.kitchen, h.pool, h.livingRoom} {
+		total += m.cost * m.size
+	}
+	return total
+}
+
 func SimpleFunctionalPattern() {
-	h := newHouse(graniteKitchen(), tilePool(), hardwoodRoom(250))
+	h, err := newHouse(graniteKitchen(), tilePool(), hardwoodRoom(250))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	fmt.Println(h.kitchen.name, h.livingRoom.name, h.pool.name)
+	fmt.Println("costs", h.cost())
+
+	// every failing option is reported, not only the first one
+	_, err = newHouse(hardwoodRoom(-5), graniteKitchen(), hardwoodRoom(0))
+	fmt.Println(err)
 }
 
 // NewServer functional option pattern
 
 type srvOpts func(*Server)
 
+// Server serves HTTP on the listener opened by newServer until Shutdown is called or the context given to Serve is done
 type Server struct {
 	listener net.Listener
 	client   http.Client
+	http     http.Server
+
+	certFile, keyFile string
+	shutdownTimeout   time.Duration
 }
 
 func timeout(t int) srvOpts {
@@ -87,18 +128,158 @@ func timeout(t int) srvOpts {
 	}
 }
 
+// readTimeout limits how long reading a whole request, including the body, may take
+func readTimeout(d time.Duration) srvOpts {
+	return func(srv *Server) {
+		srv.http.ReadTimeout = d
+	}
+}
+
+// writeTimeout limits how long writing the response may take
+func writeTimeout(d time.Duration) srvOpts {
+	return func(srv *Server) {
+		srv.http.WriteTimeout = d
+	}
+}
+
+// idleTimeout limits how long a keep-alive connection waits for the next request
+func idleTimeout(d time.Duration) srvOpts {
+	return func(srv *Server) {
+		srv.http.IdleTimeout = d
+	}
+}
+
+func maxHeaderBytes(n int) srvOpts {
+	return func(srv *Server) {
+		srv.http.MaxHeaderBytes = n
+	}
+}
+
+// handler replaces the default, empty, http.ServeMux
+func handler(h http.Handler) srvOpts {
+	return func(srv *Server) {
+		srv.http.Handler = h
+	}
+}
+
+// tlsKeyPair serves HTTPS using a PEM encoded certificate and key, newServer fails if they cannot be loaded
+func tlsKeyPair(certFile, keyFile string) srvOpts {
+	return func(srv *Server) {
+		srv.certFile, srv.keyFile = certFile, keyFile
+	}
+}
+
+// shutdownTimeout is how long Serve waits for in-flight requests once its context is done
+func shutdownTimeout(d time.Duration) srvOpts {
+	return func(srv *Server) {
+		srv.shutdownTimeout = d
+	}
+}
+
 func newServer(addr string, options ...srvOpts) (*Server, error) {
+	srv := &Server{shutdownTimeout: 5 * time.Second}
+	srv.http.Handler = http.NewServeMux()
+	for _, option := range options {
+		option(srv)
+	}
+	// load the key pair before listening so a bad certificate does not leave a port open
+	if srv.certFile != "" || srv.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(srv.certFile, srv.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		srv.http.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
 	listen, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
-	srv := &Server{listener: listen}
+	srv.listener = listen
+	return srv, nil
+}
 
-	for _, option := range options {
-		option(srv)
+// Addr is the address being listened on, useful when newServer was given port 0
+func (srv *Server) Addr() net.Addr {
+	return srv.listener.Addr()
+}
+
+// Serve blocks serving requests until ctx is done or Shutdown is called.
+// When ctx is done the server shuts down gracefully, waiting up to the shutdownTimeout for requests in flight.
+// A graceful stop returns nil.
+func (srv *Server) Serve(ctx context.Context) error {
+	served := make(chan error, 1)
+	go func() {
+		if srv.http.TLSConfig != nil {
+			// the certificates are already in TLSConfig so no files are needed
+			served <- srv.http.ServeTLS(srv.listener, "", "")
+			return
+		}
+		served <- srv.http.Serve(srv.listener)
+	}()
+
+	select {
+	case err := <-served:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		// ctx is already done so the shutdown needs a context of its own
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), srv.shutdownTimeout)
+		defer cancel()
+		err := srv.Shutdown(shutdownCtx)
+		<-served
+		return err
 	}
-	return srv, nil
+}
+
+// Shutdown stops accepting connections and waits for active requests to finish or for ctx to be done.
+// It also closes the listener when Serve was never called.
+func (srv *Server) Shutdown(ctx context.Context) error {
+	err := srv.http.Shutdown(ctx)
+	if cErr := srv.listener.Close(); cErr != nil && !errors.Is(cErr, net.ErrClosed) && err == nil {
+		err = cErr
+	}
+	return err
 }
 
 // to := timeout(30)
 // srv, err := newServer("80", to)
+
+func ExampleFunctionalServer() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "hello from the functional options server")
+	})
+	// port 0 lets the operating system pick a free port
+	srv, err := newServer("127.0.0.1:0",
+		handler(mux),
+		timeout(5),
+		readTimeout(5*time.Second),
+		writeTimeout(5*time.Second),
+		idleTimeout(30*time.Second),
+		maxHeaderBytes(1<<16),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx) }()
+
+	// the client configured by the timeout option calls the server itself
+	res, err := srv.client.Get("http://" + srv.Addr().String() + "/hello")
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		fmt.Print(res.Status, " ", string(body))
+	}
+
+	stop()
+	fmt.Println("served until cancelled, error:", <-served)
+}
@@ -0,0 +1,279 @@
+package algorithms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func helloMux() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	})
+	return mux
+}
+
+func TestServerOptions(t *testing.T) {
+	srv, err := newServer("127.0.0.1:0",
+		readTimeout(time.Second),
+		writeTimeout(2*time.Second),
+		idleTimeout(3*time.Second),
+		maxHeaderBytes(4096),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Shutdown(context.Background())
+
+	if srv.http.ReadTimeout != time.Second || srv.http.WriteTimeout != 2*time.Second ||
+		srv.http.IdleTimeout != 3*time.Second || srv.http.MaxHeaderBytes != 4096 {
+		t.Errorf("options were not applied: read %v write %v idle %v header bytes %d",
+			srv.http.ReadTimeout, srv.http.WriteTimeout, srv.http.IdleTimeout, srv.http.MaxHeaderBytes)
+	}
+	if _, ok := srv.http.Handler.(*http.ServeMux); !ok {
+		t.Errorf("default handler is %T; want *http.ServeMux", srv.http.Handler)
+	}
+}
+
+func TestServerServeUntilCancelled(t *testing.T) {
+	srv, err := newServer("127.0.0.1:0", handler(helloMux()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx) }()
+
+	res, err := http.Get("http://" + srv.Addr().String() + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "hello" {
+		t.Errorf("body = %q; want hello", body)
+	}
+
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("Serve returned %v after cancel; want nil", err)
+	}
+	if _, err := net.Dial("tcp", srv.Addr().String()); err == nil {
+		t.Error("listener is still accepting connections after shutdown")
+	}
+}
+
+func TestServerShutdownWaitsForRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "finished")
+	})
+	srv, err := newServer("127.0.0.1:0", handler(mux))
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(context.Background()) }()
+
+	got := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + srv.Addr().String() + "/slow")
+		if err != nil {
+			got <- err.Error()
+			return
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		got <- string(body)
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- srv.Shutdown(context.Background()) }()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned %v before the request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	if body := <-got; body != "finished" {
+		t.Errorf("in-flight request got %q; want finished", body)
+	}
+	if err := <-shutdown; err != nil {
+		t.Error(err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve returned %v after Shutdown; want nil", err)
+	}
+}
+
+func TestServerShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stuck", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	srv, err := newServer("127.0.0.1:0", handler(mux), shutdownTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx) }()
+	requested := make(chan struct{})
+	go func() {
+		defer close(requested)
+		// the server gives up on the request so the client may see an error, only the body needs closing
+		if res, err := http.Get("http://" + srv.Addr().String() + "/stuck"); err == nil {
+			res.Body.Close()
+		}
+	}()
+	<-started
+
+	cancel()
+	if err := <-served; err != context.DeadlineExceeded {
+		t.Errorf("Serve returned %v; want the shutdown deadline to be exceeded", err)
+	}
+	close(release)
+	<-requested
+}
+
+// writeKeyPair writes a self-signed certificate for 127.0.0.1 into dir
+func writeKeyPair(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "lessons test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestServerTLS(t *testing.T) {
+	certFile, keyFile := writeKeyPair(t, t.TempDir())
+	srv, err := newServer("127.0.0.1:0", handler(helloMux()), tlsKeyPair(certFile, keyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx) }()
+	// registered first so it runs last, after the client has let go of its connections
+	t.Cleanup(func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("Serve returned %v after cancel; want nil", err)
+		}
+	})
+
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certPEM) {
+		t.Fatal("no certificate found in " + certFile)
+	}
+	transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+	t.Cleanup(transport.CloseIdleConnections)
+	client := http.Client{Transport: transport}
+	res, err := client.Get("https://" + srv.Addr().String() + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.TLS == nil {
+		t.Error("response was not served over TLS")
+	}
+}
+
+func TestServerBadKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := newServer("127.0.0.1:0", tlsKeyPair(filepath.Join(dir, "missing.pem"), filepath.Join(dir, "missing.key"))); err == nil {
+		t.Error("newServer should fail when the key pair cannot be loaded")
+	}
+}
+
+func TestNewHouse(t *testing.T) {
+	var tests = []struct {
+		name     string
+		opts     []houseOptions
+		wantErrs int
+		wantCost int
+	}{
+		{"defaults", nil, 0, 3 * 1000 * 300},
+		{"all options", []houseOptions{graniteKitchen(), tilePool(), hardwoodRoom(250)}, 0, 2*1000*300 + 1000*250},
+		{"negative room", []houseOptions{hardwoodRoom(-5)}, 1, 0},
+		{"every failure reported", []houseOptions{hardwoodRoom(-5), tilePool(), hardwoodRoom(0)}, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := newHouse(tt.opts...)
+			if tt.wantErrs > 0 {
+				joined, ok := err.(interface{ Unwrap() []error })
+				if !ok || len(joined.Unwrap()) != tt.wantErrs {
+					t.Fatalf("err = %v; want %d joined errors", err, tt.wantErrs)
+				}
+				if h != nil {
+					t.Error("a failed house should be nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := h.cost(); got != tt.wantCost {
+				t.Errorf("cost() = %d; want %d", got, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestTilePoolChangesThePool(t *testing.T) {
+	h, _ := newHouse(tilePool())
+	if h.pool.name != "tile" || h.kitchen.name != "steel" {
+		t.Errorf("tilePool set kitchen %q pool %q; want kitchen steel pool tile", h.kitchen.name, h.pool.name)
+	}
+}
@@ -0,0 +1,145 @@
+package algorithms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// history keeps executed commands on an undo stack and undone commands on a redo stack
+// executing a new command clears the redo stack, the same way an editor forgets redo after typing
+// when a journal writer is set every action is appended to it as one JSON object per line so the session can be replayed
+type history struct {
+	done    Stack[command]
+	undone  Stack[command]
+	journal *json.Encoder
+}
+
+var (
+	errNothingToUndo = errors.New("nothing to undo")
+	errNothingToRedo = errors.New("nothing to redo")
+)
+
+// newHistory journals to w, a nil w keeps no journal
+func newHistory(w io.Writer) *history {
+	h := &history{}
+	if w != nil {
+		h.journal = json.NewEncoder(w)
+	}
+	return h
+}
+
+// execute runs c and records it even when it fails because its effect has already been applied and can still be undone
+func (h *history) execute(c command) error {
+	err := c.execute()
+	h.done.Push(c)
+	h.undone.Clear()
+	if jErr := h.record(journalExecute, c); jErr != nil {
+		return jErr
+	}
+	return err
+}
+
+func (h *history) undo() error {
+	c, ok := h.done.Pop()
+	if !ok {
+		return errNothingToUndo
+	}
+	if err := c.undo(); err != nil {
+		h.done.Push(c)
+		return err
+	}
+	h.undone.Push(c)
+	return h.record(journalUndo, nil)
+}
+
+func (h *history) redo() error {
+	c, ok := h.undone.Pop()
+	if !ok {
+		return errNothingToRedo
+	}
+	err := c.execute()
+	h.done.Push(c)
+	if jErr := h.record(journalRedo, nil); jErr != nil {
+		return jErr
+	}
+	return err
+}
+
+const (
+	journalExecute = "execute"
+	journalUndo    = "undo"
+	journalRedo    = "redo"
+)
+
+// journalEntry is one line of the journal
+// Command and Amount are only set for execute because undo and redo refer to commands already in the history
+type journalEntry struct {
+	Action  string `json:"action"`
+	Command string `json:"command,omitempty"`
+	Amount  int    `json:"amount,omitempty"`
+}
+
+func (h *history) record(action string, c command) error {
+	if h.journal == nil {
+		return nil
+	}
+	entry := journalEntry{Action: action}
+	switch c := c.(type) {
+	case nil:
+	case *feed:
+		entry.Command, entry.Amount = "feed", c.food
+	case *play:
+		entry.Command, entry.Amount = "play", c.activity
+	case *poop:
+		entry.Command, entry.Amount = "poop", c.amount
+	default:
+		return fmt.Errorf("cannot journal command of type %T", c)
+	}
+	return h.journal.Encode(entry)
+}
+
+// replayJournal rebuilds a tomagachi session from the journal written by a history
+// errors returned by the commands themselves are part of the session, only a malformed journal stops the replay
+func replayJournal(r io.Reader) (*tomagachi, error) {
+	t := newTomagachi()
+	h := newHistory(nil)
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var entry journalEntry
+		err := dec.Decode(&entry)
+		if err == io.EOF {
+			return t, nil
+		}
+		if err != nil {
+			return t, fmt.Errorf("journal entry %d: %w", line, err)
+		}
+		switch entry.Action {
+		case journalExecute:
+			var c command
+			switch entry.Command {
+			case "feed":
+				c = t.feedPet(entry.Amount)
+			case "play":
+				c = t.playPet(entry.Amount)
+			case "poop":
+				c = t.poopPet(entry.Amount)
+			default:
+				return t, fmt.Errorf("journal entry %d: unknown command %q", line, entry.Command)
+			}
+			h.execute(c)
+		case journalUndo:
+			if err := h.undo(); err != nil {
+				return t, fmt.Errorf("journal entry %d: %w", line, err)
+			}
+		case journalRedo:
+			// like execute a failing command is part of the session, only an empty redo stack means the journal is wrong
+			if err := h.redo(); errors.Is(err, errNothingToRedo) {
+				return t, fmt.Errorf("journal entry %d: %w", line, err)
+			}
+		default:
+			return t, fmt.Errorf("journal entry %d: unknown action %q", line, entry.Action)
+		}
+	}
+}
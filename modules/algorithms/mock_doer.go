@@ -0,0 +1,269 @@
+package algorithms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// mockClient is a scriptable doer test double
+// each expectation matches requests by method, path, headers and body and answers with a canned response or an error
+// every request is recorded so a test can assert what was sent, a request no expectation matches fails with errUnexpectedRequest
+// the zero value has no expectations
+type mockClient struct {
+	mu           sync.Mutex
+	expectations []*expectation
+	calls        []recordedCall
+}
+
+var errUnexpectedRequest = errors.New("mock: unexpected request")
+
+// recordedCall is a request the mock received
+type recordedCall struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// cannedResponse is what an expectation answers with
+type cannedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// expectation is built with on and configured by chaining its methods
+// mock.on("GET", "/users").withHeader("Accept", "application/json").respond(200, `[]`)
+type expectation struct {
+	method  string
+	path    string
+	header  http.Header
+	body    *string // nil matches any body
+	res     cannedResponse
+	err     error
+	limit   int // 0 answers any number of requests
+	matched int
+}
+
+// on adds an expectation for requests with the method and URL path
+// expectations are checked in the order they were added, the first one that matches and is not used up answers
+func (m *mockClient) on(method, path string) *expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &expectation{method: method, path: path, header: http.Header{}, res: cannedResponse{Status: http.StatusOK}}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// withHeader requires the request header key to have value
+func (e *expectation) withHeader(key, value string) *expectation {
+	e.header.Add(key, value)
+	return e
+}
+
+// withBody requires the request body to be exactly body
+func (e *expectation) withBody(body string) *expectation {
+	e.body = &body
+	return e
+}
+
+func (e *expectation) respond(status int, body string) *expectation {
+	e.res.Status, e.res.Body = status, body
+	return e
+}
+
+func (e *expectation) respondHeader(key, value string) *expectation {
+	if e.res.Header == nil {
+		e.res.Header = http.Header{}
+	}
+	e.res.Header.Add(key, value)
+	return e
+}
+
+// fail makes Do return err instead of a response, e.g. to simulate a timeout
+func (e *expectation) fail(err error) *expectation {
+	e.err = err
+	return e
+}
+
+// times limits the expectation to n requests, afterwards it no longer matches
+func (e *expectation) times(n int) *expectation {
+	e.limit = n
+	return e
+}
+
+func (e *expectation) once() *expectation {
+	return e.times(1)
+}
+
+func (e *expectation) matches(c recordedCall) bool {
+	if e.limit > 0 && e.matched >= e.limit {
+		return false
+	}
+	if e.method != c.Method || e.path != c.Path {
+		return false
+	}
+	for key, values := range e.header {
+		got := c.Header.Values(key)
+		for _, want := range values {
+			if !contains(got, want) {
+				return false
+			}
+		}
+	}
+	return e.body == nil || *e.body == c.Body
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Do fulfills the doer interface
+func (m *mockClient) Do(req *http.Request) (*http.Response, error) {
+	call := recordedCall{Method: req.Method, Path: req.URL.Path, Header: req.Header.Clone()}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		call.Body = string(body)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+	for _, e := range m.expectations {
+		if !e.matches(call) {
+			continue
+		}
+		e.matched++
+		if e.err != nil {
+			return nil, e.err
+		}
+		header := e.res.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", e.res.Status, http.StatusText(e.res.Status)),
+			StatusCode:    e.res.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(e.res.Body)),
+			ContentLength: int64(len(e.res.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", errUnexpectedRequest, call.Method, call.Path)
+}
+
+// recorded returns a copy of every request received so far in order
+func (m *mockClient) recorded() []recordedCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := make([]recordedCall, len(m.calls))
+	copy(calls, m.calls)
+	return calls
+}
+
+// verify reports every expectation limited by once or times that did not receive all of its requests
+func (m *mockClient) verify() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for _, e := range m.expectations {
+		if e.limit > 0 && e.matched < e.limit {
+			errs = append(errs, fmt.Errorf("mock: %s %s expected %d requests, got %d", e.method, e.path, e.limit, e.matched))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// interaction is one request and its response in a recorded session file
+type interaction struct {
+	Request  recordedCall   `json:"request"`
+	Response cannedResponse `json:"response"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// loadSession programs a mock that replays the interactions in a JSON session file
+// every interaction answers exactly one matching request and verify reports the ones that were never replayed
+func loadSession(path string) (*mockClient, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var session []interaction
+	if err := json.NewDecoder(f).Decode(&session); err != nil {
+		return nil, fmt.Errorf("session %s: %w", path, err)
+	}
+
+	m := &mockClient{}
+	for _, in := range session {
+		e := m.on(in.Request.Method, in.Request.Path).once()
+		for key, values := range in.Request.Header {
+			for _, v := range values {
+				e.withHeader(key, v)
+			}
+		}
+		if in.Request.Body != "" {
+			e.withBody(in.Request.Body)
+		}
+		if in.Error != "" {
+			e.fail(errors.New(in.Error))
+			continue
+		}
+		e.res = in.Response
+		if e.res.Status == 0 {
+			e.res.Status = http.StatusOK
+		}
+	}
+	return m, nil
+}
+
+func ExampleMockDoer() {
+	mock := &mockClient{}
+	mock.on("GET", "/users/1").withHeader("Accept", "application/json").
+		respond(http.StatusOK, `{"name":"Akira"}`).respondHeader("Content-Type", "application/json")
+	mock.on("POST", "/users").withBody(`{"name":"Lola"}`).respond(http.StatusCreated, "").once()
+	mock.on("DELETE", "/users/1").fail(errors.New("connection reset"))
+
+	// code under test only knows about the doer interface
+	var client doer = mock
+	send := func(method, path, body string) {
+		req, _ := http.NewRequest(method, "http://example.com"+path, strings.NewReader(body))
+		req.Header.Set("Accept", "application/json")
+		res, err := client.Do(req)
+		if err != nil {
+			fmt.Println(method, path, "error:", err)
+			return
+		}
+		b, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		fmt.Println(method, path, res.Status, res.Header.Get("Content-Type"), string(b))
+	}
+	send("GET", "/users/1", "")
+	send("POST", "/users", `{"name":"Lola"}`)
+	send("POST", "/users", `{"name":"Lola"}`) // once has been used up
+	send("DELETE", "/users/1", "")
+
+	for _, c := range mock.recorded() {
+		fmt.Println("recorded", c.Method, c.Path, c.Body)
+	}
+	fmt.Println("verify:", mock.verify())
+}
@@ -0,0 +1,117 @@
+package algorithms
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func do(t *testing.T, d doer, method, path, body string, header ...string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(method, "http://example.com"+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	return d.Do(req)
+}
+
+func TestMockDoerMatching(t *testing.T) {
+	m := &mockClient{}
+	m.on("GET", "/a").withHeader("X-Team", "blue").respond(200, "blue team")
+	m.on("GET", "/a").respond(200, "anyone")
+	m.on("PUT", "/a").withBody("exact").respond(204, "")
+	boom := errors.New("boom")
+	m.on("GET", "/fail").fail(boom)
+
+	var tests = []struct {
+		name, method, path, body string
+		header                   []string
+		wantStatus               int
+		wantBody                 string
+		wantErr                  error
+	}{
+		{"header match", "GET", "/a", "", []string{"X-Team", "blue"}, 200, "blue team", nil},
+		{"falls through to next", "GET", "/a", "", []string{"X-Team", "red"}, 200, "anyone", nil},
+		{"body match", "PUT", "/a", "exact", nil, 204, "", nil},
+		{"body mismatch", "PUT", "/a", "other", nil, 0, "", errUnexpectedRequest},
+		{"canned error", "GET", "/fail", "", nil, 0, "", boom},
+		{"unknown path", "GET", "/nothing", "", nil, 0, "", errUnexpectedRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := do(t, m, tt.method, tt.path, tt.body, tt.header...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v; want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+				t.Errorf("got %d %q; want %d %q", res.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+
+	calls := m.recorded()
+	if len(calls) != len(tests) {
+		t.Fatalf("recorded %d calls; want %d", len(calls), len(tests))
+	}
+	if calls[2].Body != "exact" || calls[0].Header.Get("X-Team") != "blue" {
+		t.Errorf("recorded calls lost details: %+v", calls)
+	}
+}
+
+func TestMockDoerVerify(t *testing.T) {
+	m := &mockClient{}
+	m.on("POST", "/once").once()
+	m.on("POST", "/twice").times(2)
+	do(t, m, "POST", "/once", "")
+	if _, err := do(t, m, "POST", "/once", ""); !errors.Is(err, errUnexpectedRequest) {
+		t.Errorf("second request to a once expectation = %v; want errUnexpectedRequest", err)
+	}
+	do(t, m, "POST", "/twice", "")
+	err := m.verify()
+	if err == nil || !strings.Contains(err.Error(), "/twice expected 2 requests, got 1") {
+		t.Errorf("verify() = %v; want /twice reported", err)
+	}
+}
+
+func TestLoadSession(t *testing.T) {
+	m, err := loadSession(filepath.Join("testdata", "session.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := do(t, m, "GET", "/health", "")
+	if err != nil || res.StatusCode != 200 {
+		t.Fatalf("GET /health = %v, %v", res, err)
+	}
+	res, err = do(t, m, "POST", "/orders", `{"item":"pencil"}`, "Content-Type", "application/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 201 || res.Header.Get("Location") != "/orders/7" {
+		t.Errorf("POST /orders = %d %v", res.StatusCode, res.Header)
+	}
+	if err := m.verify(); err == nil {
+		t.Error("verify should report the interaction that was never replayed")
+	}
+	if _, err := do(t, m, "GET", "/orders/7", ""); err == nil || err.Error() != "connection refused" {
+		t.Errorf("GET /orders/7 err = %v; want connection refused", err)
+	}
+	if err := m.verify(); err != nil {
+		t.Error(err)
+	}
+	if _, err := loadSession(filepath.Join("testdata", "missing.json")); err == nil {
+		t.Error("loading a missing session should fail")
+	}
+}
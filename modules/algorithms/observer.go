@@ -0,0 +1,398 @@
+package algorithms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Observer Pattern in Go
+// a one-to-many dependency, when the subject changes state every observer is notified, see basics.txt.
+// Bus is an in-process event bus: publishers send events on named topics and never know who observes them,
+// subscribers observe the topics matching a pattern.
+//
+// Topic names are segments separated by dots like "orders.created".
+// In a pattern "*" matches exactly one segment and a final ">" matches one or more, so "orders.*" and "orders.>" both observe "orders.created".
+//
+// A synchronous subscriber runs in the publisher's goroutine, Publish returns once it has handled the event.
+// An asynchronous subscriber has a buffered channel and its own goroutine, when the buffer is full
+// its overflowPolicy either drops the event or blocks the publisher until there is room.
+// A handler that panics is recovered and counted in Panics, one bad subscriber cannot take down the publisher or the others.
+
+var ErrBusClosed = errors.New("event bus is closed")
+
+// ErrInvalidTopic is returned for a topic or pattern with an empty segment, or a wildcard where it is not allowed
+type ErrInvalidTopic struct {
+	Topic string
+}
+
+func (e ErrInvalidTopic) Error() string {
+	return fmt.Sprint("invalid topic: ", e.Topic)
+}
+
+// ErrInvalidBuffer is returned by Subscribe for an asynchronous subscriber with a negative buffer
+type ErrInvalidBuffer struct {
+	Buffer int
+}
+
+func (e ErrInvalidBuffer) Error() string {
+	return fmt.Sprint("invalid subscriber buffer: ", e.Buffer)
+}
+
+// Event is what a subscriber receives, Topic is where it was published which matters for a wildcard subscriber
+type Event[T any] struct {
+	Topic   string
+	Payload T
+}
+
+// overflowPolicy decides what Publish does when an asynchronous subscriber's buffer is full
+type overflowPolicy int
+
+const (
+	// dropWhenFull throws the event away and counts it in Dropped, the publisher never waits for a slow subscriber
+	dropWhenFull overflowPolicy = iota
+	// blockWhenFull makes Publish wait until there is room or its context is done
+	blockWhenFull
+)
+
+type envelope struct {
+	topic   string
+	payload any
+}
+
+// Subscription is returned by Subscribe, Unsubscribe stops it
+type Subscription struct {
+	bus     *Bus
+	pattern []string
+	accepts func(payload any) bool // false for payloads of another type than the subscriber's
+	handle  func(e envelope)
+
+	// only set for an asynchronous subscriber
+	async   bool
+	policy  overflowPolicy
+	buffer  int
+	events  chan envelope
+	done    chan struct{} // closed by Unsubscribe or Close, the events still buffered are delivered first
+	stop    sync.Once
+	dropped atomic.Int64
+
+	panics atomic.Int64
+}
+
+type subscribeOption func(*Subscription)
+
+// deliverAsync gives the subscriber a buffer of events and a goroutine of its own, by default it runs synchronously
+// a buffer of 0 hands every event straight to the subscriber's goroutine, a negative one is rejected with ErrInvalidBuffer
+func deliverAsync(buffer int, policy overflowPolicy) subscribeOption {
+	return func(s *Subscription) {
+		s.async = true
+		s.buffer = buffer
+		s.policy = policy
+	}
+}
+
+// Dropped is how many events a dropWhenFull subscriber has missed because its buffer was full
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Panics is how many times the subscriber's handler panicked, the events it panicked on are lost
+func (s *Subscription) Panics() int64 {
+	return s.panics.Load()
+}
+
+// deliver calls the handler, recovering a panic so the publisher and an asynchronous subscriber's goroutine carry on
+func (s *Subscription) deliver(e envelope) {
+	defer func() {
+		if v := recover(); v != nil {
+			s.panics.Add(1)
+		}
+	}()
+	s.handle(e)
+}
+
+// Unsubscribe stops new events reaching the subscriber, an asynchronous one still handles the events already buffered.
+// It is safe to call more than once and from inside the subscriber's own handler.
+func (s *Subscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	for i, sub := range s.bus.subs {
+		if sub == s {
+			s.bus.subs = append(s.bus.subs[:i:i], s.bus.subs[i+1:]...)
+			break
+		}
+	}
+	s.bus.mu.Unlock()
+	s.stop.Do(func() {
+		if s.async {
+			close(s.done)
+		}
+	})
+}
+
+// run delivers an asynchronous subscriber's events until it is stopped, then drains what is left in the buffer
+func (s *Subscription) run() {
+	defer s.bus.delivering.Done()
+	for {
+		select {
+		case e := <-s.events:
+			s.deliver(e)
+		case <-s.done:
+			for {
+				select {
+				case e := <-s.events:
+					s.deliver(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Subscription) send(ctx context.Context, e envelope) error {
+	if !s.async {
+		s.deliver(e)
+		return nil
+	}
+	if s.policy == dropWhenFull {
+		select {
+		case s.events <- e:
+		default:
+			s.dropped.Add(1)
+		}
+		return nil
+	}
+	select {
+	case s.events <- e:
+	case <-s.done:
+		// unsubscribed while the publisher was waiting for room
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Bus delivers events from publishers to subscribers, it is safe for concurrent use
+type Bus struct {
+	mu     sync.RWMutex
+	subs   []*Subscription // in the order they subscribed, which is the order they are notified in
+	closed bool
+
+	publishing sync.WaitGroup // Publish calls in progress
+	delivering sync.WaitGroup // asynchronous subscribers' goroutines
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// segments splits name into its segments, wildcards are only allowed in a pattern
+func segments(name string, pattern bool) ([]string, error) {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		wildcard := p == "*" || p == ">"
+		switch {
+		case p == "":
+			return nil, ErrInvalidTopic{name}
+		case wildcard && !pattern:
+			return nil, ErrInvalidTopic{name}
+		case p == ">" && i != len(parts)-1:
+			return nil, ErrInvalidTopic{name}
+		}
+	}
+	return parts, nil
+}
+
+// matches reports whether the topic's segments match the pattern's
+func matches(pattern, topic []string) bool {
+	for i, p := range pattern {
+		if p == ">" {
+			return len(topic) > i
+		}
+		if i >= len(topic) || p != "*" && p != topic[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
+
+func (b *Bus) subscribe(pattern string, s *Subscription, opts []subscribeOption) (*Subscription, error) {
+	var err error
+	if s.pattern, err = segments(pattern, true); err != nil {
+		return nil, err
+	}
+	s.bus = b
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.buffer < 0 {
+		return nil, ErrInvalidBuffer{s.buffer}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+	if s.async {
+		s.events = make(chan envelope, s.buffer)
+		s.done = make(chan struct{})
+		b.delivering.Add(1)
+		go s.run()
+	}
+	b.subs = append(b.subs, s)
+	return s, nil
+}
+
+func (b *Bus) publish(ctx context.Context, topic string, payload any) error {
+	name, err := segments(topic, false)
+	if err != nil {
+		return err
+	}
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrBusClosed
+	}
+	b.publishing.Add(1)
+	defer b.publishing.Done()
+	// the lock is not held while delivering so a handler can publish, subscribe or unsubscribe itself
+	var matched []*Subscription
+	for _, s := range b.subs {
+		if matches(s.pattern, name) && s.accepts(payload) {
+			matched = append(matched, s)
+		}
+	}
+	b.mu.RUnlock()
+
+	e := envelope{topic, payload}
+	for _, s := range matched {
+		if err := s.send(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops accepting events and subscribers, waits for the Publish calls in progress
+// and then for every asynchronous subscriber to handle the events left in its buffer.
+// When ctx is done first it returns ctx.Err(), the subscribers carry on draining in the background and calling Close again waits for them.
+// A handler must not call Close, it would wait for the Publish running the handler or for the handler's own goroutine
+// and never return before ctx is done, start it in another goroutine instead.
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = nil
+	b.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		b.publishing.Wait()
+		for _, s := range subs {
+			s.Unsubscribe()
+		}
+		b.delivering.Wait()
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Topic publishes and observes events whose payload is a T
+type Topic[T any] struct {
+	bus  *Bus
+	name string
+}
+
+// NewTopic returns the topic called name on b, the name cannot contain wildcards
+func NewTopic[T any](b *Bus, name string) Topic[T] {
+	return Topic[T]{bus: b, name: name}
+}
+
+// Publish notifies every subscriber of the topic, it returns ErrBusClosed after Close
+// and ctx.Err() when a blockWhenFull subscriber's buffer stays full until ctx is done.
+func (t Topic[T]) Publish(ctx context.Context, payload T) error {
+	return t.bus.publish(ctx, t.name, payload)
+}
+
+// Subscribe observes this topic only
+func (t Topic[T]) Subscribe(handler func(Event[T]), opts ...subscribeOption) (*Subscription, error) {
+	if _, err := segments(t.name, false); err != nil {
+		return nil, err
+	}
+	return Subscribe(t.bus, t.name, handler, opts...)
+}
+
+// Subscribe observes every topic matching pattern whose payload is a T, Subscribe[any] observes every payload
+func Subscribe[T any](b *Bus, pattern string, handler func(Event[T]), opts ...subscribeOption) (*Subscription, error) {
+	return b.subscribe(pattern, &Subscription{
+		accepts: func(payload any) bool {
+			_, ok := payload.(T)
+			return ok
+		},
+		handle: func(e envelope) {
+			handler(Event[T]{e.topic, e.payload.(T)})
+		},
+	}, opts)
+}
+
+type order struct {
+	id    int
+	total float64
+}
+
+func ExampleEventBus() {
+	ctx := context.Background()
+	bus := NewBus()
+	created := NewTopic[order](bus, "orders.created")
+	shipped := NewTopic[order](bus, "orders.shipped")
+	signups := NewTopic[string](bus, "users.created")
+
+	// synchronous subscribers are notified in the order they subscribed, before Publish returns
+	created.Subscribe(func(e Event[order]) {
+		fmt.Printf("billing: charge %.2f for order %d\n", e.Payload.total, e.Payload.id)
+	})
+	audit, _ := Subscribe(bus, ">", func(e Event[any]) {
+		fmt.Println("audit:", e.Topic, e.Payload)
+	})
+	Subscribe(bus, "orders.*", func(e Event[order]) {
+		fmt.Println("orders dashboard:", e.Topic, e.Payload.id)
+	})
+
+	created.Publish(ctx, order{1, 9.99})
+	shipped.Publish(ctx, order{1, 9.99})
+	signups.Publish(ctx, "gopher")
+	audit.Unsubscribe()
+	created.Publish(ctx, order{2, 25})
+
+	// an asynchronous subscriber with a buffer of 1 and the drop policy never slows the publisher down
+	// while its handler is busy with the first event the second fills the buffer and the rest are dropped
+	busy := make(chan struct{})
+	release := make(chan struct{})
+	var emailed []int
+	slow, _ := shipped.Subscribe(func(e Event[order]) {
+		if e.Payload.id == 10 {
+			close(busy)
+			<-release
+		}
+		emailed = append(emailed, e.Payload.id)
+	}, deliverAsync(1, dropWhenFull))
+	shipped.Publish(ctx, order{10, 1})
+	<-busy
+	for id := 11; id <= 14; id++ {
+		shipped.Publish(ctx, order{id, 1})
+	}
+	close(release)
+
+	// Close drains the buffered events before returning, after it Publish fails
+	fmt.Println("close:", bus.Close(ctx))
+	fmt.Println("emailed", emailed, "dropped", slow.Dropped())
+	fmt.Println("publish after close:", created.Publish(ctx, order{3, 1}))
+}
@@ -0,0 +1,261 @@
+package algorithms
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"lessons/leaktest"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.shipped", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "orders.created.eu", false},
+		{"*.created", "users.created", true},
+		{"orders.>", "orders.created.eu", true},
+		{"orders.>", "orders", false},
+		{">", "users.created", true},
+		{"orders", "orders.created", false},
+	}
+	for _, tt := range tests {
+		pattern, _ := segments(tt.pattern, true)
+		topic, _ := segments(tt.topic, false)
+		if got := matches(pattern, topic); got != tt.want {
+			t.Errorf("matches(%q, %q) = %t; want %t", tt.pattern, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestInvalidTopics(t *testing.T) {
+	bus := NewBus()
+	for _, pattern := range []string{"", "orders.", "a..b", ">.created"} {
+		if _, err := Subscribe(bus, pattern, func(Event[int]) {}); !errors.As(err, &ErrInvalidTopic{}) {
+			t.Errorf("Subscribe(%q) = %v; want ErrInvalidTopic", pattern, err)
+		}
+	}
+	wild := NewTopic[int](bus, "orders.*")
+	if err := wild.Publish(context.Background(), 1); !errors.As(err, &ErrInvalidTopic{}) {
+		t.Errorf("publishing to a wildcard = %v; want ErrInvalidTopic", err)
+	}
+	if _, err := wild.Subscribe(func(Event[int]) {}); !errors.As(err, &ErrInvalidTopic{}) {
+		t.Errorf("Topic.Subscribe with a wildcard = %v; want ErrInvalidTopic", err)
+	}
+}
+
+func TestInvalidBuffer(t *testing.T) {
+	leaktest.Check(t)
+	bus := NewBus()
+	topic := NewTopic[int](bus, "n")
+	if _, err := topic.Subscribe(func(Event[int]) {}, deliverAsync(-1, dropWhenFull)); err != (ErrInvalidBuffer{-1}) {
+		t.Errorf("Subscribe with a buffer of -1 = %v; want ErrInvalidBuffer", err)
+	}
+	// the bus is still usable, a buffer of 0 is allowed
+	if _, err := topic.Subscribe(func(Event[int]) {}, deliverAsync(0, blockWhenFull)); err != nil {
+		t.Errorf("Subscribe with a buffer of 0 = %v", err)
+	}
+	if err := topic.Publish(context.Background(), 1); err != nil {
+		t.Error(err)
+	}
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncDeliveryOrderAndTypes(t *testing.T) {
+	bus := NewBus()
+	ctx := context.Background()
+	var got []string
+	NewTopic[int](bus, "n.a").Subscribe(func(e Event[int]) { got = append(got, "first "+e.Topic) })
+	Subscribe(bus, "n.*", func(e Event[int]) { got = append(got, "wildcard "+e.Topic) })
+	Subscribe(bus, "n.*", func(e Event[string]) { got = append(got, "strings "+e.Topic) })
+
+	NewTopic[int](bus, "n.a").Publish(ctx, 1)
+	NewTopic[string](bus, "n.b").Publish(ctx, "x")
+	want := []string{"first n.a", "wildcard n.a", "strings n.b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("delivered %q; want %q", got, want)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	leaktest.Check(t)
+	bus := NewBus()
+	ctx := context.Background()
+	topic := NewTopic[int](bus, "n")
+	var syncGot, asyncGot []int
+	var sub *Subscription
+	sub, _ = topic.Subscribe(func(e Event[int]) {
+		syncGot = append(syncGot, e.Payload)
+		// unsubscribing from inside the handler must not deadlock
+		sub.Unsubscribe()
+	})
+	asub, _ := topic.Subscribe(func(e Event[int]) { asyncGot = append(asyncGot, e.Payload) }, deliverAsync(10, blockWhenFull))
+	topic.Publish(ctx, 1)
+	asub.Unsubscribe()
+	asub.Unsubscribe()
+	topic.Publish(ctx, 2)
+	if err := bus.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(syncGot, []int{1}) || !slices.Equal(asyncGot, []int{1}) {
+		t.Errorf("sync got %v, async got %v; want only the event before unsubscribing", syncGot, asyncGot)
+	}
+}
+
+func TestSlowSubscriberDrops(t *testing.T) {
+	leaktest.Check(t)
+	bus := NewBus()
+	ctx := context.Background()
+	topic := NewTopic[int](bus, "n")
+	release := make(chan struct{})
+	var got []int
+	slow, _ := topic.Subscribe(func(e Event[int]) {
+		<-release
+		got = append(got, e.Payload)
+	}, deliverAsync(2, dropWhenFull))
+	var fast []int
+	topic.Subscribe(func(e Event[int]) { fast = append(fast, e.Payload) })
+
+	// the publisher is never held up, however slow the subscriber
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			topic.Publish(ctx, i)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a dropWhenFull subscriber")
+	}
+	close(release)
+	if err := bus.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(fast) != 100 {
+		t.Errorf("the synchronous subscriber got %d events; want 100", len(fast))
+	}
+	// one event in the handler and two buffered at most
+	if len(got) < 1 || len(got) > 3 || int64(len(got))+slow.Dropped() != 100 {
+		t.Errorf("slow subscriber got %d and dropped %d; want at most 3 and the rest dropped", len(got), slow.Dropped())
+	}
+}
+
+func TestSlowSubscriberBlocks(t *testing.T) {
+	leaktest.Check(t)
+	bus := NewBus()
+	topic := NewTopic[int](bus, "n")
+	release := make(chan struct{})
+	var got []int
+	topic.Subscribe(func(e Event[int]) {
+		<-release
+		got = append(got, e.Payload)
+	}, deliverAsync(1, blockWhenFull))
+
+	// one event is being handled and one fills the buffer, the third has to wait
+	ctx := context.Background()
+	topic.Publish(ctx, 1)
+	topic.Publish(ctx, 2)
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := topic.Publish(timeout, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Publish to a full subscriber = %v; want %v", err, context.DeadlineExceeded)
+	}
+
+	published := make(chan error)
+	go func() { published <- topic.Publish(ctx, 4) }()
+	close(release)
+	if err := <-published; err != nil {
+		t.Errorf("Publish once the subscriber caught up = %v", err)
+	}
+	if err := bus.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []int{1, 2, 4}) {
+		t.Errorf("got %v; want [1 2 4] in order", got)
+	}
+}
+
+func TestCloseDrains(t *testing.T) {
+	leaktest.Check(t)
+	bus := NewBus()
+	ctx := context.Background()
+	topic := NewTopic[int](bus, "n")
+	var mu sync.Mutex
+	var got []int
+	release := make(chan struct{})
+	topic.Subscribe(func(e Event[int]) {
+		<-release
+		mu.Lock()
+		got = append(got, e.Payload)
+		mu.Unlock()
+	}, deliverAsync(10, blockWhenFull))
+	for i := 0; i < 5; i++ {
+		topic.Publish(ctx, i)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close with a stuck subscriber = %v; want %v", err, context.DeadlineExceeded)
+	}
+	if err := topic.Publish(ctx, 5); err != ErrBusClosed {
+		t.Errorf("Publish after Close = %v; want %v", err, ErrBusClosed)
+	}
+	if _, err := topic.Subscribe(func(Event[int]) {}); err != ErrBusClosed {
+		t.Errorf("Subscribe after Close = %v; want %v", err, ErrBusClosed)
+	}
+
+	close(release)
+	if err := bus.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(got, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("Close delivered %v; want every buffered event", got)
+	}
+}
+
+func TestHandlerPanics(t *testing.T) {
+	leaktest.Check(t)
+	bus := NewBus()
+	ctx := context.Background()
+	topic := NewTopic[int](bus, "n")
+	boom := func(e Event[int]) {
+		if e.Payload%2 == 0 {
+			panic("boom")
+		}
+	}
+	syncSub, _ := topic.Subscribe(boom)
+	asyncSub, _ := topic.Subscribe(boom, deliverAsync(10, blockWhenFull))
+	var got []int
+	topic.Subscribe(func(e Event[int]) { got = append(got, e.Payload) })
+
+	for i := 0; i < 4; i++ {
+		if err := topic.Publish(ctx, i); err != nil {
+			t.Fatalf("Publish(%d) = %v", i, err)
+		}
+	}
+	if err := bus.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// the subscribers after a panicking one are still notified
+	if !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Errorf("got %v; want [0 1 2 3]", got)
+	}
+	if syncSub.Panics() != 2 || asyncSub.Panics() != 2 {
+		t.Errorf("sync subscriber panicked %d times, async %d; want 2 each", syncSub.Panics(), asyncSub.Panics())
+	}
+}
@@ -173,8 +173,10 @@ func mapsAndKeys() {
 }
 
 // permutateRune the values at index i to len(a)-1.
+// once i reaches the last index there is nothing left to swap so a is a complete permutation.
+// see PermutationsFunc in combinatorics.go for the generic version.
 func permutateRune(a []rune, f func([]rune), i int) {
-	if i > len(a) {
+	if i >= len(a)-1 {
 		f(a)
 		return
 	}
@@ -256,6 +258,7 @@ func ExampleRecursiveFunctions() {
 		If you have a problem that could best be solved by recursion but are afraid of blowing out memory, you can always use a channel. Mind you this will be significantly slower but it will work. "
 
 		I think the idea is to just implement recursion as simply and easily to understand as possible since performance difference will be neglegible.
+		When the depth really is a problem see recursion.go, a trampoline does what the channel would without the goroutine.
 	*/
 	var answer int
 	answer = directRecursion(0)
@@ -1,94 +1,285 @@
 package algorithms
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
 
-// queue
+// Queue
 // commonly referred to as First In, First Out (FIFO)
 // https://en.wikipedia.org/wiki/FIFO_(computing_and_electronics)
-type queue []int
+//
+// Queue is backed by a ring buffer, head is the index of the front item and size is how many items follow it.
+// Reslicing a slice with (*q)[1:] never releases the front of the backing array, a ring buffer re-uses it instead.
+// The zero value is an empty, unbounded queue. Queue is not safe to share across goroutines, see SyncQueue.
+type Queue[T any] struct {
+	items []T
+	head  int
+	size  int
+	limit int // 0 means unbounded
+}
+
+// minQueueCap is the smallest backing array, it avoids growing one item at a time
+const minQueueCap = 4
 
-// Enqueue adds the item to the back of the order
-func (q *queue) Enqueue(i int) {
-	*q = append(*q, i)
+// NewQueue returns a queue holding at most limit items, a limit of 0 or less is unbounded.
+func NewQueue[T any](limit int) *Queue[T] {
+	if limit < 0 {
+		limit = 0
+	}
+	return &Queue[T]{limit: limit}
 }
 
-// Dequeue removes the item from the front of the order
-func (q *queue) Dequeue() int {
-	// (*q)[n] means [n] is converted to *Point
-	dequeue := (*q)[0]
-	*q = (*q)[1:len(*q)]
-	return dequeue
+// Enqueue adds the item to the back of the order.
+// It never blocks, it reports false when a bounded queue is full.
+func (q *Queue[T]) Enqueue(v T) bool {
+	if q.limit > 0 && q.size == q.limit {
+		return false
+	}
+	if q.size == len(q.items) {
+		q.resize(q.size * 2)
+	}
+	q.items[(q.head+q.size)%len(q.items)] = v
+	q.size++
+	return true
 }
 
-func (q *queue) String() string {
-	return fmt.Sprint(*q)
+// TryDequeue removes the item from the front of the order.
+// ok is false when the queue is empty instead of panicking like an out of range index would.
+func (q *Queue[T]) TryDequeue() (v T, ok bool) {
+	if q.size == 0 {
+		return v, false
+	}
+	var zero T
+	v = q.items[q.head]
+	q.items[q.head] = zero // release the reference so the garbage collector can reclaim it
+	q.head = (q.head + 1) % len(q.items)
+	q.size--
+	// give memory back once the queue has drained to a quarter of its backing array
+	if len(q.items) > minQueueCap && q.size <= len(q.items)/4 {
+		q.resize(len(q.items) / 2)
+	}
+	return v, true
 }
 
-// AltQueue
-// This is a alternative implementation pattern
-type AltQueue struct {
-	intQueue
+// Peek returns the front item without removing it.
+func (q *Queue[T]) Peek() (v T, ok bool) {
+	if q.size == 0 {
+		return v, false
+	}
+	return q.items[q.head], true
+}
+
+// Len is the number of queued items.
+func (q *Queue[T]) Len() int {
+	return q.size
+}
+
+// Limit is the maximum number of items, 0 when the queue is unbounded.
+func (q *Queue[T]) Limit() int {
+	return q.limit
+}
+
+// resize copies the items, front first, into a new backing array of n slots
+func (q *Queue[T]) resize(n int) {
+	if n < minQueueCap {
+		n = minQueueCap
+	}
+	if q.limit > 0 && n > q.limit {
+		n = q.limit
+	}
+	items := make([]T, n)
+	for i := 0; i < q.size; i++ {
+		items[i] = q.items[(q.head+i)%len(q.items)]
+	}
+	q.items = items
+	q.head = 0
+}
+
+func (q *Queue[T]) String() string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i := 0; i < q.size; i++ {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprint(&sb, q.items[(q.head+i)%len(q.items)])
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// SyncQueue is a Queue guarded by a mutex so it can be shared across goroutines.
+// Enqueue blocks while a bounded queue is full and Dequeue blocks while it is empty,
+// the Try variants return immediately instead.
+type SyncQueue[T any] struct {
+	mu       sync.Mutex
+	notEmpty sync.Cond
+	notFull  sync.Cond
+	q        Queue[T]
+	closed   bool
+}
+
+// NewSyncQueue returns a concurrency-safe queue holding at most limit items, a limit of 0 or less is unbounded.
+func NewSyncQueue[T any](limit int) *SyncQueue[T] {
+	sq := &SyncQueue[T]{q: *NewQueue[T](limit)}
+	sq.notEmpty.L = &sq.mu
+	sq.notFull.L = &sq.mu
+	return sq
+}
+
+// Enqueue adds the item to the back of the order, waiting for room when the queue is full.
+// It reports false if the queue is closed.
+func (sq *SyncQueue[T]) Enqueue(v T) bool {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	for !sq.closed && !sq.q.Enqueue(v) {
+		sq.notFull.Wait()
+	}
+	if sq.closed {
+		return false
+	}
+	sq.notEmpty.Signal()
+	return true
+}
+
+// TryEnqueue adds the item without waiting, it reports false if the queue is full or closed.
+func (sq *SyncQueue[T]) TryEnqueue(v T) bool {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	if sq.closed || !sq.q.Enqueue(v) {
+		return false
+	}
+	sq.notEmpty.Signal()
+	return true
+}
+
+// Dequeue removes the front item, waiting for one when the queue is empty.
+// ok is false once the queue is closed and drained.
+func (sq *SyncQueue[T]) Dequeue() (v T, ok bool) {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	for {
+		if v, ok = sq.q.TryDequeue(); ok {
+			sq.notFull.Signal()
+			return v, true
+		}
+		if sq.closed {
+			return v, false
+		}
+		sq.notEmpty.Wait()
+	}
+}
+
+// TryDequeue removes the front item without waiting.
+func (sq *SyncQueue[T]) TryDequeue() (v T, ok bool) {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	if v, ok = sq.q.TryDequeue(); ok {
+		sq.notFull.Signal()
+	}
+	return v, ok
 }
-type intQueue []int
 
-func (q *AltQueue) Enqueue(i int) {
-	q.intQueue = append(q.intQueue, i)
+// Peek returns the front item without removing it.
+func (sq *SyncQueue[T]) Peek() (T, bool) {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	return sq.q.Peek()
 }
 
-func (q *AltQueue) Dequeue() int {
-	dequeue := q.intQueue[0]
-	q.intQueue = q.intQueue[1:len(q.intQueue)]
-	return dequeue
+// Len is the number of queued items.
+func (sq *SyncQueue[T]) Len() int {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	return sq.q.Len()
 }
 
-func (q AltQueue) String() string {
-	return fmt.Sprint(q.intQueue)
+// Close wakes every waiting goroutine. Enqueue fails from then on,
+// Dequeue keeps returning the remaining items until the queue is drained.
+func (sq *SyncQueue[T]) Close() {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	sq.closed = true
+	sq.notEmpty.Broadcast()
+	sq.notFull.Broadcast()
+}
+
+func (sq *SyncQueue[T]) String() string {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	return sq.q.String()
+}
+
+// AltQueue
+// This is a alternative implementation pattern
+// embedding promotes the methods of Queue[int] so AltQueue needs none of its own
+type AltQueue struct {
+	Queue[int]
 }
 
 func ExampleQueue() {
-	var q *queue = new(queue)
+	var q *Queue[int] = NewQueue[int](0)
 	fmt.Println("Queue is first in, first out")
 	q.Enqueue(5)
 	q.Enqueue(6)
 	q.Enqueue(9)
-	fmt.Println(*q)
-	fmt.Println(q.Dequeue())
-	fmt.Println(q.Dequeue())
-	fmt.Println(q.Dequeue())
-	fmt.Println(*q)
-
-	var q2 queue
-	fmt.Println("Queue is first in, first out, already ptr")
-	q2.Enqueue(5)
-	q2.Enqueue(6)
-	q2.Enqueue(9)
-	fmt.Println(q2)
-	fmt.Println(q2.Dequeue())
-	fmt.Println(q2.Dequeue())
-	fmt.Println(q2.Dequeue())
-	fmt.Println(q2)
+	fmt.Println(q)
+	fmt.Println(q.TryDequeue())
+	fmt.Println(q.TryDequeue())
+	fmt.Println(q.TryDequeue())
+	// the queue is empty, ok is false rather than a panic
+	fmt.Println(q.TryDequeue())
+	fmt.Println(q)
+
+	bounded := NewQueue[string](2)
+	fmt.Println("Bounded queue rejects items once it is full")
+	fmt.Println(bounded.Enqueue("first"))
+	fmt.Println(bounded.Enqueue("second"))
+	fmt.Println(bounded.Enqueue("third"))
+	fmt.Println(bounded.Peek())
+	fmt.Println(bounded, bounded.Len())
 
+	sq := NewSyncQueue[int](1)
+	fmt.Println("SyncQueue blocks the producer until the consumer makes room")
+	go func() {
+		for i := 0; i < 3; i++ {
+			sq.Enqueue(i)
+		}
+		sq.Close()
+	}()
+	for {
+		v, ok := sq.Dequeue()
+		if !ok {
+			break
+		}
+		fmt.Println("dequeued", v)
+	}
 }
+
 func ExampleAltQueue() {
 	var q *AltQueue = new(AltQueue)
-	fmt.Println("Queue is first in, first out111")
+	fmt.Println("Queue is first in, first out")
 	q.Enqueue(5)
 	q.Enqueue(6)
 	q.Enqueue(9)
 	fmt.Println(q)
-	fmt.Println(q.Dequeue())
-	fmt.Println(q.Dequeue())
-	fmt.Println(q.Dequeue())
+	fmt.Println(q.TryDequeue())
+	fmt.Println(q.TryDequeue())
+	fmt.Println(q.TryDequeue())
 	fmt.Println(q)
 
+	// the zero value is ready to use
 	var q2 AltQueue
-	fmt.Println("Queue is first in, first out 222")
+	fmt.Println("Queue is first in, first out, not a ptr")
 	q2.Enqueue(5)
 	q2.Enqueue(6)
 	q2.Enqueue(9)
-	fmt.Println(q2)
-	fmt.Println(q2.Dequeue())
-	fmt.Println(q2.Dequeue())
-	fmt.Println(q2.Dequeue())
-	fmt.Println(q2)
+	// String has a pointer receiver so the address is needed to print the items
+	fmt.Println(&q2)
+	fmt.Println(q2.TryDequeue())
+	fmt.Println(q2.TryDequeue())
+	fmt.Println(q2.TryDequeue())
+	fmt.Println(&q2)
 }
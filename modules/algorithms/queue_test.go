@@ -0,0 +1,134 @@
+package algorithms
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestQueueFIFO(t *testing.T) {
+	var q Queue[int] // zero value is an unbounded queue
+	for i := 0; i < 100; i++ {
+		q.Enqueue(i)
+	}
+	if q.Len() != 100 {
+		t.Fatalf("Len() = %d; want 100", q.Len())
+	}
+	for i := 0; i < 100; i++ {
+		v, ok := q.TryDequeue()
+		if !ok || v != i {
+			t.Fatalf("TryDequeue() = %d, %v; want %d, true", v, ok, i)
+		}
+	}
+	if v, ok := q.TryDequeue(); ok {
+		t.Errorf("TryDequeue() on empty queue = %d, true; want false", v)
+	}
+	if _, ok := q.Peek(); ok {
+		t.Error("Peek() on empty queue should not be ok")
+	}
+	if len(q.items) > minQueueCap {
+		t.Errorf("backing array kept %d slots after draining; want %d", len(q.items), minQueueCap)
+	}
+}
+
+func TestQueueWrapAround(t *testing.T) {
+	q := NewQueue[int](0)
+	var want []int // plain slice used as the reference FIFO
+	// enqueue two, dequeue one so head keeps moving around the ring while it grows
+	for i := 0; i < 50; i++ {
+		q.Enqueue(2 * i)
+		q.Enqueue(2*i + 1)
+		want = append(want, 2*i, 2*i+1)
+		v, _ := q.TryDequeue()
+		if v != want[0] {
+			t.Fatalf("TryDequeue() = %d; want %d", v, want[0])
+		}
+		want = want[1:]
+	}
+	for _, w := range want {
+		if v, _ := q.TryDequeue(); v != w {
+			t.Fatalf("TryDequeue() = %d; want %d", v, w)
+		}
+	}
+}
+
+func TestQueueBounded(t *testing.T) {
+	q := NewQueue[string](2)
+	var tests = []struct {
+		v    string
+		want bool
+	}{
+		{"a", true},
+		{"b", true},
+		{"c", false},
+	}
+	for _, tt := range tests {
+		if got := q.Enqueue(tt.v); got != tt.want {
+			t.Errorf("Enqueue(%q) = %v; want %v", tt.v, got, tt.want)
+		}
+	}
+	if v, _ := q.Peek(); v != "a" {
+		t.Errorf("Peek() = %q; want a", v)
+	}
+	q.TryDequeue()
+	if !q.Enqueue("c") {
+		t.Error("Enqueue after TryDequeue should have room")
+	}
+	if got := q.String(); got != "[b c]" {
+		t.Errorf("String() = %s; want [b c]", got)
+	}
+}
+
+func TestSyncQueueConcurrent(t *testing.T) {
+	const producers, perProducer = 8, 500
+	sq := NewSyncQueue[int](16)
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				sq.Enqueue(i)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		sq.Close()
+	}()
+
+	var count, sum int
+	for {
+		v, ok := sq.Dequeue()
+		if !ok {
+			break
+		}
+		count++
+		sum += v
+	}
+	if want := producers * perProducer; count != want {
+		t.Errorf("dequeued %d items; want %d", count, want)
+	}
+	if want := producers * perProducer * (perProducer - 1) / 2; sum != want {
+		t.Errorf("sum = %d; want %d", sum, want)
+	}
+	if sq.Enqueue(1) || sq.TryEnqueue(1) {
+		t.Error("Enqueue on a closed queue should fail")
+	}
+}
+
+func TestSyncQueueTry(t *testing.T) {
+	sq := NewSyncQueue[int](1)
+	if !sq.TryEnqueue(1) {
+		t.Fatal("TryEnqueue on empty queue failed")
+	}
+	if sq.TryEnqueue(2) {
+		t.Error("TryEnqueue on full queue should not block or succeed")
+	}
+	if v, ok := sq.TryDequeue(); !ok || v != 1 {
+		t.Errorf("TryDequeue() = %d, %v; want 1, true", v, ok)
+	}
+	if _, ok := sq.TryDequeue(); ok {
+		t.Error("TryDequeue on empty queue should not be ok")
+	}
+}
@@ -0,0 +1,144 @@
+package algorithms
+
+import (
+	"fmt"
+)
+
+// Stack-safe recursion
+// Go does not optimize tail calls, see ExampleRecursiveFunctions, so every recursive call costs a stack frame
+// and a recursion without a base condition like infiniteRecursion only stops when the runtime kills the program.
+// These helpers keep the recursive style while bounding what it costs.
+
+// Step is one step of a trampolined computation, either the final value or the call to make next
+// a tail call returns Call(next) instead of calling itself so the stack is unwound before the next step runs
+type Step[T any] struct {
+	value T
+	next  func() Step[T]
+}
+
+// Done ends a trampolined computation with v
+func Done[T any](v T) Step[T] {
+	return Step[T]{value: v}
+}
+
+// Call continues a trampolined computation with next
+func Call[T any](next func() Step[T]) Step[T] {
+	return Step[T]{next: next}
+}
+
+// Trampoline runs the steps one after the other in a loop, in constant stack space however many steps there are
+func Trampoline[T any](s Step[T]) T {
+	for s.next != nil {
+		s = s.next()
+	}
+	return s.value
+}
+
+// tailRecursionStep is tailRecursion rewritten for Trampoline
+func tailRecursionStep(number, product int) Step[int] {
+	product = product + number
+	if number == 0 {
+		return Done(product)
+	}
+	return Call(func() Step[int] {
+		return tailRecursionStep(number-1, product)
+	})
+}
+
+// Cache stores the results of a memoized function, plug in a bounded or concurrency-safe implementation as needed
+type Cache[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Put(key K, value V)
+}
+
+// MapCache is an unbounded Cache, it is not safe for concurrent use
+type MapCache[K comparable, V any] map[K]V
+
+func (c MapCache[K, V]) Get(key K) (V, bool) {
+	v, ok := c[key]
+	return v, ok
+}
+
+func (c MapCache[K, V]) Put(key K, value V) {
+	c[key] = value
+}
+
+// Memoize returns f with its results stored in cache
+// f receives the memoized function as self and must recurse through it so the recursive calls are cached too
+func Memoize[K comparable, V any](cache Cache[K, V], f func(self func(K) V, key K) V) func(K) V {
+	var self func(K) V
+	self = func(key K) V {
+		if v, ok := cache.Get(key); ok {
+			return v
+		}
+		v := f(self, key)
+		cache.Put(key, v)
+		return v
+	}
+	return self
+}
+
+// ErrRecursionDepth is returned by a function made with Guard when it recursed deeper than MaxDepth
+type ErrRecursionDepth struct {
+	MaxDepth int
+}
+
+func (e ErrRecursionDepth) Error() string {
+	return fmt.Sprint("recursion too deep: max depth of ", e.MaxDepth, " reached")
+}
+
+// Guard returns f limited to maxDepth nested calls, the call that would go deeper returns ErrRecursionDepth instead
+// f receives the guarded function as self and must recurse through it. Every top level call starts at depth 0
+// so the returned function is safe for concurrent use if f is.
+func Guard[A, R any](maxDepth int, f func(self func(A) (R, error), arg A) (R, error)) func(A) (R, error) {
+	return func(arg A) (R, error) {
+		var depth int
+		var self func(A) (R, error)
+		self = func(arg A) (R, error) {
+			if depth >= maxDepth {
+				var zero R
+				return zero, ErrRecursionDepth{maxDepth}
+			}
+			depth++
+			defer func() { depth-- }()
+			return f(self, arg)
+		}
+		return self(arg)
+	}
+}
+
+func ExampleStackSafeRecursion() {
+	// a million nested calls of tailRecursion would need a million stack frames, the trampoline needs one
+	fmt.Println("sum of 1 to 1000000:", Trampoline(tailRecursionStep(1000000, 0)))
+
+	// naive fibonacci makes an exponential number of calls, memoized every n is computed once
+	var calls int
+	fib := Memoize[int, int](MapCache[int, int]{}, func(fib func(int) int, n int) int {
+		calls++
+		if n < 2 {
+			return n
+		}
+		return fib(n-1) + fib(n-2)
+	})
+	fmt.Println("fib(90):", fib(90), "in", calls, "calls")
+
+	// infiniteRecursion with a guard fails instead of exhausting the stack
+	infinite := Guard(1000, func(self func(int) (int, error), n int) (int, error) {
+		return self(n + 1)
+	})
+	_, err := infinite(0)
+	fmt.Println(err)
+
+	factorial := Guard(10, func(self func(int) (int, error), n int) (int, error) {
+		if n <= 1 {
+			return 1, nil
+		}
+		f, err := self(n - 1)
+		if err != nil {
+			return 0, err
+		}
+		return n * f, nil
+	})
+	fmt.Println(factorial(5))
+	fmt.Println(factorial(20))
+}
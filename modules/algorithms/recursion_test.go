@@ -0,0 +1,82 @@
+package algorithms
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTrampolineMatchesTailRecursion(t *testing.T) {
+	for _, n := range []int{0, 1, 5, 100} {
+		want := n * (n + 1) / 2
+		if got := Trampoline(tailRecursionStep(n, 0)); got != want {
+			t.Errorf("Trampoline(tailRecursionStep(%d, 0)) = %d; want %d", n, got, want)
+		}
+	}
+}
+
+func TestTrampolineDeep(t *testing.T) {
+	// deep enough that the recursive version would need hundreds of megabytes of stack
+	const n = 10000000
+	if got := Trampoline(tailRecursionStep(n, 0)); got != n*(n+1)/2 {
+		t.Errorf("Trampoline(tailRecursionStep(%d, 0)) = %d", n, got)
+	}
+}
+
+// countingCache is a Cache that counts its hits to show the cache can be swapped
+type countingCache struct {
+	MapCache[int, int]
+	hits int
+}
+
+func (c *countingCache) Get(key int) (int, bool) {
+	v, ok := c.MapCache.Get(key)
+	if ok {
+		c.hits++
+	}
+	return v, ok
+}
+
+func TestMemoize(t *testing.T) {
+	cache := &countingCache{MapCache: MapCache[int, int]{}}
+	var calls int
+	fib := Memoize[int, int](cache, func(fib func(int) int, n int) int {
+		calls++
+		if n < 2 {
+			return n
+		}
+		return fib(n-1) + fib(n-2)
+	})
+	if got := fib(50); got != 12586269025 {
+		t.Errorf("fib(50) = %d; want 12586269025", got)
+	}
+	if calls != 51 {
+		t.Errorf("fib(50) computed %d values; want 51", calls)
+	}
+	hits := cache.hits
+	fib(50)
+	if calls != 51 || cache.hits != hits+1 {
+		t.Errorf("a second fib(50) made %d calls and %d cache hits; want 0 calls, 1 hit", calls-51, cache.hits-hits)
+	}
+}
+
+func TestGuard(t *testing.T) {
+	countdown := Guard(5, func(self func(int) (int, error), n int) (int, error) {
+		if n == 0 {
+			return 0, nil
+		}
+		depth, err := self(n - 1)
+		return depth + 1, err
+	})
+	if got, err := countdown(4); got != 4 || err != nil {
+		t.Errorf("countdown(4) = %d, %v; want 4, nil", got, err)
+	}
+	_, err := countdown(5)
+	var depthErr ErrRecursionDepth
+	if !errors.As(err, &depthErr) || depthErr.MaxDepth != 5 {
+		t.Errorf("countdown(5) error = %v; want ErrRecursionDepth{5}", err)
+	}
+	// the depth starts over for every top level call
+	if _, err := countdown(4); err != nil {
+		t.Errorf("countdown(4) after a failed call = %v; want nil", err)
+	}
+}
@@ -0,0 +1,42 @@
+package algorithms
+
+import (
+	"regexp"
+
+	"lessons/registry"
+)
+
+// init registers every algorithms lesson so it can be run with "lessons run algorithms.<Name>"
+func init() {
+	const category = "algorithms"
+	for _, l := range []registry.Lesson{
+		{Name: "algorithms.ExampleAlgoPatterns", Description: "compare and increment a slice only when the value is new", Run: ExampleAlgoPatterns},
+		{Name: "algorithms.ExampleAltQueue", Description: "FIFO queue built by embedding the generic Queue", Run: ExampleAltQueue},
+		{Name: "algorithms.ExampleAnimalFactories", Description: "factory generators, factories that hold their own defaults", Run: ExampleAnimalFactories},
+		{Name: "algorithms.ExampleCombinatorics", Description: "generic permutations, combinations and power sets through iterators", Run: ExampleCombinatorics},
+		{Name: "algorithms.ExampleCommandPattern", Description: "command pattern executing tomagachi tasks", Run: ExampleCommandPattern},
+		{Name: "algorithms.ExampleCommandDispatcher", Description: "commands executed on a worker pool keeping each receiver in order", Run: ExampleCommandDispatcher,
+			// pets are looked after in parallel so the lines printed by their commands interleave
+			Volatile: []*regexp.Regexp{regexp.MustCompile(`(?m)^(fed energy|spent energy|total poop): \d+$`)}},
+		{Name: "algorithms.ExampleEventBus", Description: "observer pattern as an event bus with typed topics, wildcards and async subscribers", Run: ExampleEventBus},
+		{Name: "algorithms.ExampleFactoryFunctions", Description: "factory functions that close over a default attribute", Run: ExampleFactoryFunctions},
+		{Name: "algorithms.ExampleFactoryRegistry", Description: "abstract factories picked by kind and validated against a config schema", Run: ExampleFactoryRegistry},
+		{Name: "algorithms.ExampleFunctionalServer", Description: "functional options configuring an HTTP server with graceful shutdown", Run: ExampleFunctionalServer},
+		{Name: "algorithms.ExampleMockDoer", Description: "scriptable HTTP test double matching requests and recording calls", Run: ExampleMockDoer},
+		{Name: "algorithms.ExamplePermutations", Description: "permutations of a rune slice through recursion", Run: ExamplePermutations},
+		{Name: "algorithms.ExampleQueue", Description: "generic ring buffer queue with bounded and concurrency-safe variants", Run: ExampleQueue},
+		{Name: "algorithms.ExampleRecursiveFunctions", Description: "direct recursion, tail recursion and the deferred call order", Run: ExampleRecursiveFunctions},
+		{Name: "algorithms.ExampleSingletons", Description: "race-free singleton created once with sync.Once and lazily created named instances", Run: ExampleSingletons,
+			// the counter lives in package variables so it keeps growing when the lesson runs twice
+			Volatile: []*regexp.Regexp{regexp.MustCompile(`(?m)^\d+$`)}},
+		{Name: "algorithms.ExampleStatePattern", Description: "generic state machine with guards and hooks driving the tomagachi, exported as Graphviz DOT", Run: ExampleStatePattern},
+		{Name: "algorithms.ExampleStackSafeRecursion", Description: "trampolines, memoized recursion and a recursion depth guard", Run: ExampleStackSafeRecursion},
+		{Name: "algorithms.ExampleStack", Description: "generic LIFO stack with a max depth, used to evaluate reverse polish notation", Run: ExampleStack},
+		{Name: "algorithms.InterfaceFactory", Description: "factory returning an interface instead of a struct", Run: InterfaceFactory},
+		{Name: "algorithms.SimpleFactory", Description: "simple factory returning a struct pointer", Run: SimpleFactory},
+		{Name: "algorithms.SimpleFunctionalPattern", Description: "validated functional options used to build and cost a house", Run: SimpleFunctionalPattern},
+	} {
+		l.Category = category
+		registry.Register(l)
+	}
+}
@@ -3,6 +3,7 @@ package algorithms
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // singleton pattern isn't as useful in Go because packages should be used to control single instance of variables and objects
@@ -11,40 +12,128 @@ import (
 // Notice no pointer is needed in the method receivers because global variables are being mutated
 // This example also demonstrates why traditional singleton implementations are not as relevant in Go
 // You could simply have a global variable in the package with a Get and Set function to manipulate it.
+//
+// The globals are shared by every goroutine so they have to be synchronized, += on a plain int is a data race.
+// counter and project are atomic so the methods need no lock, even on an instance left over from before a reset.
+// singletonMu only guards once and instance so a test can swap once for a fresh one, NewInstance holds it for reading
+// and once.Do orders the write of instance before every caller's read.
 type singleton struct{}
 
 var (
-	instance *singleton
-	once     sync.Once
-	counter  int
-	project  string
+	singletonMu sync.RWMutex
+	instance    *singleton
+	once        = new(sync.Once)
+	counter     atomic.Int64
+	project     atomic.Pointer[string]
 )
 
 func (s singleton) AddCounter(i int) int {
-	counter += i
-	return counter
+	return int(counter.Add(int64(i)))
 }
 
 func (s singleton) GetCounter() int {
-	return counter
+	return int(counter.Load())
 }
 func (s singleton) GetName() string {
-	return project
+	if p := project.Load(); p != nil {
+		return *p
+	}
+	return ""
 }
 
 func NewInstance() *singleton {
+	singletonMu.RLock()
+	defer singletonMu.RUnlock()
 	once.Do(func() {
 		instance = &singleton{}
-		counter = 1
-		project = "Mine"
+		counter.Store(1)
+		name := "Mine"
+		project.Store(&name)
 	})
 	return instance
 }
 
+// onceMap lazily creates one value per key, the named version of a singleton
+// init runs at most once per key, even when many goroutines ask for the same key at the same time,
+// and its result, error included, is remembered. Different keys never wait for each other.
+type onceMap[K comparable, V any] struct {
+	init    func(K) (V, error)
+	mu      sync.Mutex
+	entries map[K]*onceEntry[V]
+}
+
+type onceEntry[V any] struct {
+	once  sync.Once
+	value V
+	err   error
+}
+
+func newOnceMap[K comparable, V any](init func(K) (V, error)) *onceMap[K, V] {
+	return &onceMap[K, V]{init: init, entries: make(map[K]*onceEntry[V])}
+}
+
+// get returns the value for key, creating it on first use
+func (m *onceMap[K, V]) get(key K) (V, error) {
+	// the map lock is only held to find the entry, init runs under the entry's own once
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	if !ok {
+		e = &onceEntry[V]{}
+		m.entries[key] = e
+	}
+	m.mu.Unlock()
+
+	e.once.Do(func() {
+		e.value, e.err = m.init(key)
+	})
+	return e.value, e.err
+}
+
+// forget drops key so the next get initializes it again
+func (m *onceMap[K, V]) forget(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+type environment struct {
+	name     string
+	database string
+}
+
 func ExampleSingletons() {
 	s := NewInstance()
 	fmt.Println(s.AddCounter(4))
 	s2 := NewInstance()
 	fmt.Println(s2.GetCounter())
 	fmt.Println(s2.GetName())
+
+	// AddCounter is atomic so concurrent callers never lose an increment
+	var wg sync.WaitGroup
+	before := s.GetCounter()
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.AddCounter(1)
+		}()
+	}
+	wg.Wait()
+	fmt.Println("added", s.GetCounter()-before, "concurrently")
+
+	// environments are created on first use per name, e.g. a connection pool per database
+	environments := newOnceMap(func(name string) (*environment, error) {
+		switch name {
+		case "dev", "prod":
+			fmt.Println("initializing", name)
+			return &environment{name: name, database: name + ".db.local"}, nil
+		}
+		return nil, fmt.Errorf("unknown environment %q", name)
+	})
+	// "initializing dev" is printed once even though dev is asked for twice
+	dev, _ := environments.get("dev")
+	again, _ := environments.get("dev")
+	fmt.Println(dev.database, dev == again)
+	_, err := environments.get("staging")
+	fmt.Println(err)
 }
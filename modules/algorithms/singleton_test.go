@@ -0,0 +1,108 @@
+package algorithms
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// resetSingleton is the test-only hook that puts the singleton back into its never initialized state
+func resetSingleton() {
+	singletonMu.Lock()
+	defer singletonMu.Unlock()
+	once = new(sync.Once)
+	instance = nil
+	counter.Store(0)
+	project.Store(nil)
+}
+
+// RUN with go test -race to check AddCounter is free of data races
+func TestAddCounterConcurrent(t *testing.T) {
+	resetSingleton()
+	t.Cleanup(resetSingleton)
+
+	const goroutines, adds = 64, 1000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// every goroutine races NewInstance as well as AddCounter
+			s := NewInstance()
+			for i := 0; i < adds; i++ {
+				s.AddCounter(1)
+				s.GetName()
+			}
+		}()
+	}
+	wg.Wait()
+	if got, want := NewInstance().GetCounter(), 1+goroutines*adds; got != want {
+		t.Errorf("GetCounter() = %d; want %d", got, want)
+	}
+}
+
+func TestResetSingleton(t *testing.T) {
+	resetSingleton()
+	t.Cleanup(resetSingleton)
+
+	s := NewInstance()
+	s.AddCounter(10)
+	resetSingleton()
+	if got := NewInstance().GetCounter(); got != 1 {
+		t.Errorf("GetCounter() after reset = %d; want the initial 1", got)
+	}
+	if got := NewInstance().GetName(); got != "Mine" {
+		t.Errorf("GetName() after reset = %q; want Mine", got)
+	}
+}
+
+// RUN with go test -race, an instance from before a reset is read while a new one is initialized
+func TestGetNameDuringReset(t *testing.T) {
+	resetSingleton()
+	t.Cleanup(resetSingleton)
+
+	old := NewInstance()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			old.GetName()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		resetSingleton()
+		NewInstance()
+	}
+	<-done
+}
+
+func TestOnceMapInitializesEachKeyOnce(t *testing.T) {
+	var calls atomic.Int32
+	m := newOnceMap(func(key string) (int, error) {
+		calls.Add(1)
+		return len(key), nil
+	})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, key := range []string{"a", "bb", "ccc"} {
+				if v, _ := m.get(key); v != len(key) {
+					t.Errorf("get(%q) = %d; want %d", key, v, len(key))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := calls.Load(); got != 3 {
+		t.Errorf("init ran %d times; want once per key", got)
+	}
+
+	m.forget("a")
+	m.get("a")
+	if got := calls.Load(); got != 4 {
+		t.Errorf("init ran %d times after forget; want 4", got)
+	}
+}
@@ -1,43 +1,177 @@
 package algorithms
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Stack
 // commonly refers to First In, Last Out (FILO) or Last In, First Out(LIFO)
 // https://en.wikipedia.org/wiki/Stack_(abstract_data_type)
-type Stack struct {
-	slice []int
+// The zero value is an empty stack without a maximum depth.
+type Stack[T any] struct {
+	slice    []T
+	maxDepth int // 0 means unlimited
 }
 
-func (s *Stack) Push(i int) {
-	s.slice = append(s.slice, i)
+// ErrStackOverflow is returned by Push when the stack already holds MaxDepth items.
+// Check for it with errors.As to find out which limit was hit.
+type ErrStackOverflow struct {
+	MaxDepth int
 }
-func (s *Stack) Pop() int {
-	pop := s.slice[len(s.slice)-1]
+
+func (e ErrStackOverflow) Error() string {
+	return fmt.Sprint("stack overflow: max depth of ", e.MaxDepth, " reached")
+}
+
+// NewStack returns a stack holding at most maxDepth items, a maxDepth of 0 or less is unlimited.
+func NewStack[T any](maxDepth int) *Stack[T] {
+	if maxDepth < 0 {
+		maxDepth = 0
+	}
+	return &Stack[T]{maxDepth: maxDepth}
+}
+
+func (s *Stack[T]) Push(v T) error {
+	if s.maxDepth > 0 && len(s.slice) == s.maxDepth {
+		return ErrStackOverflow{s.maxDepth}
+	}
+	s.slice = append(s.slice, v)
+	return nil
+}
+
+// Pop removes the top item, ok is false when the stack is empty.
+func (s *Stack[T]) Pop() (v T, ok bool) {
+	if len(s.slice) == 0 {
+		return v, false
+	}
+	var zero T
+	v = s.slice[len(s.slice)-1]
+	s.slice[len(s.slice)-1] = zero // release the reference held by the backing array
 	s.slice = s.slice[:len(s.slice)-1]
-	return pop
+	return v, true
+}
+
+// Peek returns the top item without removing it, ok is false when the stack is empty.
+func (s *Stack[T]) Peek() (v T, ok bool) {
+	if len(s.slice) == 0 {
+		return v, false
+	}
+	return s.slice[len(s.slice)-1], true
+}
+
+func (s *Stack[T]) Len() int {
+	return len(s.slice)
 }
-func (s *Stack) Peek() int {
-	return s.slice[len(s.slice)-1]
+
+// Clear empties the stack and releases its backing array.
+func (s *Stack[T]) Clear() {
+	s.slice = nil
 }
 
-func (s Stack) String() string {
+// All returns an iterator over a snapshot of the stack from top to bottom.
+// Pushing or popping while iterating does not affect the items being walked.
+// It has the shape of iter.Seq so it can be ranged over directly once the module targets go1.23,
+// until then call it with a yield function that returns false to stop early.
+func (s *Stack[T]) All() func(yield func(T) bool) {
+	snapshot := make([]T, len(s.slice))
+	copy(snapshot, s.slice)
+	return func(yield func(T) bool) {
+		for i := len(snapshot) - 1; i >= 0; i-- {
+			if !yield(snapshot[i]) {
+				return
+			}
+		}
+	}
+}
+
+func (s Stack[T]) String() string {
 	return fmt.Sprint(s.slice)
 }
 
+// evalRPN evaluates an integer expression in reverse polish notation e.g. "3 4 + 2 *" is (3 + 4) * 2
+// a malformed expression is reported as an error because Pop reports an empty stack instead of panicking
+func evalRPN(expr string) (int, error) {
+	s := NewStack[int](32)
+	for _, token := range strings.Fields(expr) {
+		switch token {
+		case "+", "-", "*", "/":
+			b, okB := s.Pop()
+			a, okA := s.Pop()
+			if !okA || !okB {
+				return 0, fmt.Errorf("operator %s is missing an operand", token)
+			}
+			var r int
+			switch token {
+			case "+":
+				r = a + b
+			case "-":
+				r = a - b
+			case "*":
+				r = a * b
+			case "/":
+				if b == 0 {
+					return 0, errors.New("division by zero")
+				}
+				r = a / b
+			}
+			s.Push(r) // two items were just popped so there is always room
+		default:
+			n, err := strconv.Atoi(token)
+			if err != nil {
+				return 0, err
+			}
+			if err := s.Push(n); err != nil {
+				return 0, err
+			}
+		}
+	}
+	result, ok := s.Pop()
+	if !ok || s.Len() != 0 {
+		return 0, fmt.Errorf("expression %q does not reduce to a single value", expr)
+	}
+	return result, nil
+}
+
 func ExampleStack() {
-	s := new(Stack)
+	s := new(Stack[int])
 	s.Push(100)
 	s.Push(22)
 	s.Push(37)
 	s.Push(54)
 	fmt.Println(s)
 	fmt.Println(s.Peek())
-	fmt.Println("Pop", s.Pop())
+	v, _ := s.Pop()
+	fmt.Println("Pop", v)
 	fmt.Println(s)
-	fmt.Println("Pop", s.Pop())
+	v, _ = s.Pop()
+	fmt.Println("Pop", v)
 	fmt.Println(s.Peek())
 	fmt.Println(s)
+
+	fmt.Println("walking top to bottom")
+	s.All()(func(v int) bool {
+		fmt.Println(v)
+		return true
+	})
+
+	s.Clear()
+	// an empty stack reports ok as false instead of an index out of range panic
+	fmt.Println("Pop after Clear")
+	fmt.Println(s.Pop())
+
+	limited := NewStack[string](1)
+	fmt.Println(limited.Push("only"))
+	err := limited.Push("one too many")
+	var overflow ErrStackOverflow
+	if errors.As(err, &overflow) {
+		fmt.Println(err, "| limit", overflow.MaxDepth)
+	}
+
+	for _, expr := range []string{"3 4 + 2 *", "5 1 2 + 4 * + 3 -", "1 +"} {
+		result, err := evalRPN(expr)
+		fmt.Println(expr, "=", result, err)
+	}
 }
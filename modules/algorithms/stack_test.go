@@ -0,0 +1,90 @@
+package algorithms
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStackEmpty(t *testing.T) {
+	var s Stack[string]
+	if v, ok := s.Pop(); ok {
+		t.Errorf("Pop() on empty stack = %q, true; want false", v)
+	}
+	if v, ok := s.Peek(); ok {
+		t.Errorf("Peek() on empty stack = %q, true; want false", v)
+	}
+}
+
+func TestStackLIFO(t *testing.T) {
+	s := NewStack[int](0)
+	for i := 1; i <= 3; i++ {
+		s.Push(i)
+	}
+	var walked []int
+	s.All()(func(v int) bool {
+		walked = append(walked, v)
+		s.Pop() // mutating the stack must not affect the snapshot
+		return true
+	})
+	if len(walked) != 3 || walked[0] != 3 || walked[2] != 1 {
+		t.Errorf("All() walked %v; want [3 2 1]", walked)
+	}
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d; want 0", s.Len())
+	}
+
+	s.Push(7)
+	s.Push(8)
+	var first int
+	s.All()(func(v int) bool {
+		first = v
+		return false // stop after the top item
+	})
+	if first != 8 {
+		t.Errorf("All() started at %d; want 8", first)
+	}
+	s.Clear()
+	if s.Len() != 0 {
+		t.Errorf("Len() after Clear = %d; want 0", s.Len())
+	}
+}
+
+func TestStackOverflow(t *testing.T) {
+	s := NewStack[int](2)
+	if err := s.Push(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Push(2); err != nil {
+		t.Fatal(err)
+	}
+	err := s.Push(3)
+	var overflow ErrStackOverflow
+	if !errors.As(err, &overflow) || overflow.MaxDepth != 2 {
+		t.Errorf("Push on a full stack returned %v; want ErrStackOverflow{2}", err)
+	}
+}
+
+func TestEvalRPN(t *testing.T) {
+	var tests = []struct {
+		expr    string
+		want    int
+		wantErr bool
+	}{
+		{"3 4 + 2 *", 14, false},
+		{"5 1 2 + 4 * + 3 -", 14, false},
+		{"10 2 /", 5, false},
+		{"1 +", 0, true},
+		{"+", 0, true},
+		{"1 2", 0, true},
+		{"1 0 /", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := evalRPN(tt.expr)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("evalRPN(%q) = %d, %v; want %d, error %v", tt.expr, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
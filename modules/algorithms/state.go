@@ -0,0 +1,98 @@
+package algorithms
+
+import (
+	"fmt"
+	"os"
+
+	"lessons/fsm"
+)
+
+// State Pattern in Go
+// an object alters its behavior when its internal state changes, see basics.txt.
+// Instead of every method checking fields to work out what state the object is in,
+// the states and the events moving between them are declared once as an fsm.Definition.
+
+// petState is where a tomagachi is in its life
+type petState int
+
+const (
+	alive petState = iota
+	dead
+)
+
+func (s petState) String() string {
+	if s == dead {
+		return "dead"
+	}
+	return "alive"
+}
+
+// petEvent is fired by the tomagachi's commands, see command.go
+type petEvent string
+
+const (
+	fedEvent     petEvent = "feed"
+	playedEvent  petEvent = "play"
+	poopedEvent  petEvent = "poop"
+	cleanedEvent petEvent = "clean"
+)
+
+// maxPoop is the most poop a tomagachi survives
+const maxPoop = 75
+
+// newPetLife is the state machine of t, the guards read t so the poop command updates t.poop before firing
+func newPetLife(t *tomagachi) *fsm.Machine[petState, petEvent] {
+	tooMuchPoop := func() bool { return t.poop > maxPoop }
+	cleanEnough := func() bool { return t.poop <= maxPoop }
+	return fsm.MustNew(fsm.Definition[petState, petEvent]{
+		Initial: alive,
+		States:  []fsm.State[petState, petEvent]{{ID: alive}, {ID: dead}},
+		Transitions: []fsm.Transition[petState, petEvent]{
+			{From: alive, Event: fedEvent, To: alive},
+			{From: alive, Event: playedEvent, To: alive},
+			{From: alive, Event: poopedEvent, To: dead, Guard: tooMuchPoop, GuardName: "poop > 75"},
+			{From: alive, Event: poopedEvent, To: alive},
+			{From: alive, Event: cleanedEvent, To: alive},
+			{From: dead, Event: cleanedEvent, To: alive, Guard: cleanEnough, GuardName: "poop <= 75"},
+			{From: dead, Event: cleanedEvent, To: dead},
+		},
+	})
+}
+
+func ExampleStatePattern() {
+	pet := newTomagachi()
+	kill := pet.poopPet(80)
+	fmt.Println("poop:", kill.execute(), "| state", pet.life.State())
+	// a dead tomagachi rejects every event but clean, the rejected command changes nothing
+	fmt.Println("feed:", pet.feedPet(10).execute(), "| energy", pet.energy)
+	fmt.Println("can play:", pet.life.Can(playedEvent))
+	fmt.Println("undo:", kill.undo(), "| state", pet.life.State())
+
+	// a workflow with guards and entry and exit hooks
+	approvals := 0
+	review := fsm.MustNew(fsm.Definition[string, string]{
+		Initial: "draft",
+		States: []fsm.State[string, string]{
+			{ID: "draft"},
+			{ID: "in review",
+				OnEnter: func(from, event string) { fmt.Println("  reviewers notified") },
+				OnExit:  func(to, event string) { fmt.Println("  review finished:", to) }},
+			{ID: "merged", OnEnter: func(from, event string) { fmt.Println("  branch deleted") }},
+		},
+		Transitions: []fsm.Transition[string, string]{
+			{From: "draft", Event: "submit", To: "in review"},
+			{From: "in review", Event: "approve", To: "in review"},
+			{From: "in review", Event: "request changes", To: "draft"},
+			{From: "in review", Event: "merge", To: "merged", Guard: func() bool { return approvals >= 2 }, GuardName: "2 approvals"},
+		},
+	})
+	for _, event := range []string{"merge", "submit", "approve", "merge", "approve", "merge"} {
+		if event == "approve" {
+			approvals++
+		}
+		fmt.Printf("%s: %v -> %s\n", event, review.Fire(event), review.State())
+	}
+
+	// the definition documents itself, pipe this into "dot -Tsvg" to draw it
+	pet.life.DOT(os.Stdout, "tomagachi")
+}
@@ -4,20 +4,27 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"lessons/clock"
 )
 
-func access(ch chan int, wg *sync.WaitGroup) {
-	time.Sleep(time.Second)
+func access(clk clock.Clock, ch chan int, wg *sync.WaitGroup) {
+	clk.Sleep(time.Second)
 	fmt.Println("start accessing channel")
 
 	for i := range ch {
-		wg.Done()
 		fmt.Println(i)
-		time.Sleep(time.Second)
+		clk.Sleep(time.Second)
+		// Done once the work is finished so wg.Wait also waits for the last Sleep
+		wg.Done()
 	}
 }
 
 func ExampleWGLoop() {
+	wgLoop(clock.Real)
+}
+
+func wgLoop(clk clock.Clock) {
 	// un-buffered is to maintain synchronization
 	//ch := make(chan int)
 	// buffered is good for queueing
@@ -25,8 +32,9 @@ func ExampleWGLoop() {
 	ch := make(chan int, 3)
 
 	//defer
+	// wg counts the items so access has to call Done for each one, ExampleGroup counts the goroutines instead
 	var wg sync.WaitGroup
-	go access(ch, &wg)
+	go access(clk, ch, &wg)
 
 	for i := 0; i < 9; i++ {
 		// time.Sleep(time.Second) both buffered and unbuffered will block the channel until it is filled
@@ -0,0 +1,105 @@
+package concurrency
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"lessons/clock"
+	"lessons/leaktest"
+	"lessons/registry"
+)
+
+// capture runs lesson on a fake clock and returns what it printed, drive moves the clock while it runs
+func capture(t *testing.T, lesson func(clock.Clock), drive func(f *clock.Fake)) string {
+	t.Helper()
+	f := clock.NewFake(epoch)
+	go drive(f)
+	out, err := registry.Capture(registry.Lesson{Name: t.Name(), Run: func() { lesson(f) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// advanceWhileWaiting moves f forward by step whenever something waits on it, until stop is closed
+func advanceWhileWaiting(step time.Duration, stop <-chan struct{}) func(f *clock.Fake) {
+	return func(f *clock.Fake) {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if f.Waiters() > 0 {
+				f.Advance(step)
+				continue
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestSimpleTimer(t *testing.T) {
+	leaktest.Check(t)
+	out := capture(t, simpleTimer, func(f *clock.Fake) {
+		f.BlockUntil(2) // the timer and the sleep
+		f.Advance(2 * time.Second)
+	})
+	if out != "timer stopped\nAll done\n" {
+		t.Errorf("output %q; want the timer stopped before it fired", out)
+	}
+}
+
+func TestSimpleTicker(t *testing.T) {
+	leaktest.Check(t)
+	out := capture(t, simpleTicker, func(f *clock.Fake) {
+		f.BlockUntil(2) // the ticker and the sleep
+		for i := 0; i < 3; i++ {
+			f.Advance(500 * time.Millisecond)
+			f.BlockUntilTicksRead()
+		}
+		f.Advance(100 * time.Millisecond)
+	})
+	var want strings.Builder
+	for i := 1; i <= 3; i++ {
+		fmt.Fprintln(&want, "Ticker fired at", epoch.Add(time.Duration(i)*500*time.Millisecond))
+	}
+	want.WriteString("All done\n")
+	if out != want.String() {
+		t.Errorf("output\n%s\nwant\n%s", out, want.String())
+	}
+}
+
+func TestUnbufferedChanOnFakeClock(t *testing.T) {
+	leaktest.Check(t)
+	stop := make(chan struct{})
+	defer close(stop)
+	out := capture(t, unbufferedChan, advanceWhileWaiting(time.Second, stop))
+	for i := 0; i < 9; i++ {
+		if !strings.Contains(out, fmt.Sprintln("read ", i, "from ch")) {
+			t.Errorf("value %d was not read:\n%s", i, out)
+		}
+	}
+}
+
+func TestBufferedChanRoutineOnFakeClock(t *testing.T) {
+	leaktest.Check(t)
+	stop := make(chan struct{})
+	defer close(stop)
+	out := capture(t, bufferedChanRoutine, advanceWhileWaiting(time.Second, stop))
+	if n := strings.Count(out, "from ch"); n != 9 {
+		t.Errorf("read %d values; want 9:\n%s", n, out)
+	}
+}
+
+func TestWGLoopOnFakeClock(t *testing.T) {
+	leaktest.Check(t)
+	stop := make(chan struct{})
+	defer close(stop)
+	out := capture(t, wgLoop, advanceWhileWaiting(time.Second, stop))
+	if strings.Count(out, "Filled") != 9 || !strings.HasSuffix(out, "ender\n") {
+		t.Errorf("output\n%s\nwant 9 Filled lines and ender last", out)
+	}
+}
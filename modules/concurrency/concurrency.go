@@ -8,47 +8,13 @@ package concurrency
 // controlling cancellation. A `Context` carries deadlines,
 // cancellation signals, and other request-scoped values
 // across API boundaries and goroutines.
+// See HelloService in hello.go for the server.
 import (
 	"fmt"
-	"net/http"
 	"time"
-)
-
-func hello(w http.ResponseWriter, req *http.Request) {
-
-	// A `context.Context` is created for each request by
-	// the `net/http` machinery, and is available with
-	// the `Context()` method.
-	ctx := req.Context()
-	fmt.Println("server: hello handler started")
-	fmt.Fprintf(w, "initial load\n")
-	defer fmt.Println("server: hello handler ended")
-
-	// Wait for a few seconds before sending a reply to the
-	// client. This could simulate some work the server is
-	// doing. While working, keep an eye on the context's
-	// `Done()` channel for a signal that we should cancel
-	// the work and return as soon as possible.
-	select {
-	case <-time.After(10 * time.Second):
-		fmt.Fprintf(w, "context case\n")
-	case <-ctx.Done():
-		// The context's `Err()` method returns an error
-		// that explains why the `Done()` channel was
-		// closed.
-		err := ctx.Err()
-		fmt.Println("server:", err)
-		internalError := http.StatusInternalServerError
-		http.Error(w, err.Error(), internalError)
-	}
-}
 
-func ExampleCurrency() {
-	// As before, we register our handler on the "/hello"
-	// route, and start serving on the default mux
-	http.HandleFunc("/hello", hello)
-	http.ListenAndServe(":8090", nil)
-}
+	"lessons/clock"
+)
 
 // Buffered and Unbuffered channels
 // by default channels are unbuffered
@@ -125,17 +91,21 @@ func runs(runs int) func(ch chan int) {
 	}
 }
 func ExampleBufferedChanRoutine() {
+	bufferedChanRoutine(clock.Real)
+}
+
+func bufferedChanRoutine(clk clock.Clock) {
 	capacity := 3
 	buffered := make(chan int, capacity)
 	instance := runs(capacity * 3)
 	go instance(buffered)
 	// intentionall delay to simulate processing
-	time.Sleep(time.Second * 1)
+	clk.Sleep(time.Second * 1)
 
 	// read values
 	for value := range buffered {
 		fmt.Println("read ", value, "from ch")
-		time.Sleep(time.Second * 1)
+		clk.Sleep(time.Second * 1)
 	}
 	// output will vary
 	// generally the trend will start with 3 channels written intially, where 3 is the capacity
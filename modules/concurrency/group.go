@@ -0,0 +1,143 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+/*
+	ExampleWGLoop counts with a sync.WaitGroup, but the consumer has to call wg.Done for every item
+	so the counting is tied to how the items are consumed, and a failing goroutine has no way to report its error.
+	A Group counts the goroutines themselves: Go starts a function, Wait waits for all of them and returns their errors.
+	The functions share a context that is cancelled as soon as one of them fails, so the others can stop early,
+	and a limit bounds how many run at once. It is the errgroup package built from the standard library alone.
+*/
+
+// Group runs functions in goroutines and waits for them, the zero value is not usable, use NewGroup
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	sem    chan struct{} // nil without a limit
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error // in the order the functions failed
+}
+
+// NewGroup returns a Group whose functions run with no more than limit at once, fewer than 1 means no limit.
+// The context passed to the functions is derived from ctx, it is cancelled when the first function fails or Wait returns
+// and context.Cause reports the error that cancelled it.
+func NewGroup(ctx context.Context, limit int) *Group {
+	g := &Group{}
+	g.ctx, g.cancel = context.WithCancelCause(ctx)
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+	return g
+}
+
+// Go runs fn in a new goroutine, blocking while limit functions are running.
+// A panic in fn is recovered and reported by Wait as an ErrJobPanicked.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		if err := g.run(fn); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+			// the error is recorded before cancelling so it comes before the errors the others return because of it
+			g.cancel(err)
+		}
+	}()
+}
+
+// run turns a panic into an error so one bad function cannot take the whole program down
+func (g *Group) run(fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = ErrJobPanicked{v}
+		}
+	}()
+	return fn(g.ctx)
+}
+
+// Wait blocks until every function started with Go has returned, then cancels the group's context.
+// It returns every error the functions returned joined with errors.Join, nil when none failed.
+// The functions that stopped because another failed usually add context.Canceled, errors.Is finds the others among them.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(context.Canceled)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.errs...)
+}
+
+func ExampleGroup() {
+	// the producer and the consumer of ExampleWGLoop, Wait counts the goroutines so the consumer only consumes
+	g := NewGroup(context.Background(), 0)
+	ch := make(chan int, 3)
+	sum := 0
+	g.Go(func(ctx context.Context) error {
+		defer close(ch)
+		for i := 0; i < 9; i++ {
+			if !send(ctx, ch, i) {
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	g.Go(func(ctx context.Context) error {
+		for i := range ch {
+			sum += i
+		}
+		return nil
+	})
+	fmt.Println("sum", sum, "error", g.Wait())
+
+	// a limit of 2 runs the squares two at a time, every function writes its own element so no lock is needed
+	g = NewGroup(context.Background(), 2)
+	squares := make([]int, 5)
+	for i := range squares {
+		i := i
+		g.Go(func(ctx context.Context) error {
+			squares[i] = i * i
+			return nil
+		})
+	}
+	g.Wait()
+	fmt.Println("squares", squares)
+
+	// the first error cancels the others, Wait returns all of them
+	g = NewGroup(context.Background(), 0)
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		fmt.Println("sibling stopped because:", context.Cause(ctx))
+		return ctx.Err()
+	})
+	g.Go(func(ctx context.Context) error {
+		return errors.New("bad input")
+	})
+	err := g.Wait()
+	fmt.Printf("%q\n", err)
+	fmt.Println("cancelled:", errors.Is(err, context.Canceled))
+
+	// a panic becomes an error instead of crashing the program
+	g = NewGroup(context.Background(), 0)
+	g.Go(func(ctx context.Context) error {
+		var counts map[string]int
+		counts["boom"]++
+		return nil
+	})
+	err = g.Wait()
+	var panicked ErrJobPanicked
+	fmt.Println(err, errors.As(err, &panicked))
+}
@@ -0,0 +1,126 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lessons/leaktest"
+)
+
+func TestGroupWaitsForAll(t *testing.T) {
+	leaktest.Check(t)
+	g := NewGroup(context.Background(), 0)
+	var done atomic.Int64
+	for i := 0; i < 10; i++ {
+		g.Go(func(ctx context.Context) error {
+			time.Sleep(time.Millisecond)
+			done.Add(1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait = %v; want nil", err)
+	}
+	if done.Load() != 10 {
+		t.Errorf("Wait returned after %d of 10 functions", done.Load())
+	}
+}
+
+func TestGroupFirstErrorCancelsSiblings(t *testing.T) {
+	leaktest.Check(t)
+	g := NewGroup(context.Background(), 0)
+	errBad := errors.New("bad")
+	cause := make(chan error, 1)
+	g.Go(func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			cause <- context.Cause(ctx)
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return errors.New("sibling was not cancelled")
+		}
+	})
+	g.Go(func(ctx context.Context) error {
+		return errBad
+	})
+	err := g.Wait()
+	if got := <-cause; got != errBad {
+		t.Errorf("sibling cancelled by %v; want %v", got, errBad)
+	}
+	if !errors.Is(err, errBad) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait = %v; want both errors joined", err)
+	}
+	if got := err.Error(); got != "bad\ncontext canceled" {
+		t.Errorf("Wait = %q; want the first error first", got)
+	}
+}
+
+func TestGroupLimit(t *testing.T) {
+	leaktest.Check(t)
+	g := NewGroup(context.Background(), 3)
+	var running, peak atomic.Int64
+	for i := 0; i < 20; i++ {
+		g.Go(func(ctx context.Context) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+	g.Wait()
+	if peak.Load() > 3 {
+		t.Errorf("%d functions ran at once; want at most 3", peak.Load())
+	}
+}
+
+func TestGroupRecoversPanics(t *testing.T) {
+	leaktest.Check(t)
+	g := NewGroup(context.Background(), 0)
+	g.Go(func(ctx context.Context) error {
+		panic("boom")
+	})
+	err := g.Wait()
+	var panicked ErrJobPanicked
+	if !errors.As(err, &panicked) || panicked.Value != "boom" {
+		t.Errorf("Wait = %v; want ErrJobPanicked{boom}", err)
+	}
+}
+
+func TestGroupParentCancelled(t *testing.T) {
+	leaktest.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	g := NewGroup(ctx, 0)
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	cancel()
+	if err := g.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait = %v; want %v", err, context.Canceled)
+	}
+}
+
+func TestGroupWaitCancelsContext(t *testing.T) {
+	leaktest.Check(t)
+	g := NewGroup(context.Background(), 0)
+	var groupCtx context.Context
+	g.Go(func(ctx context.Context) error {
+		groupCtx = ctx
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait = %v; want nil", err)
+	}
+	if groupCtx.Err() == nil {
+		t.Error("the group's context is still live after Wait")
+	}
+}
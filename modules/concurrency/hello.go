@@ -0,0 +1,213 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"lessons/clock"
+)
+
+// HelloService is the hello handler as a configurable service
+// every request does some simulated work, keeping an eye on its context's Done() channel
+// so the work stops as soon as the client goes away or the request's own deadline passes.
+// It has its own mux instead of registering on http.DefaultServeMux, so any number of services can run in one program or test.
+type HelloService struct {
+	clk        clock.Clock
+	work       time.Duration
+	maxTimeout time.Duration
+	mux        *http.ServeMux
+
+	inFlight  atomic.Int64
+	completed atomic.Int64
+	cancelled atomic.Int64
+	timedOut  atomic.Int64
+}
+
+type helloOption func(*HelloService)
+
+// helloClock sets the clock the work and the deadlines are timed with, the default is clock.Real
+func helloClock(clk clock.Clock) helloOption {
+	return func(s *HelloService) {
+		s.clk = clk
+	}
+}
+
+// helloWork sets how long a request takes, the default is 10s
+func helloWork(d time.Duration) helloOption {
+	return func(s *HelloService) {
+		s.work = d
+	}
+}
+
+// helloMaxTimeout caps the deadline a client can ask for, the default of 0 leaves it uncapped
+func helloMaxTimeout(d time.Duration) helloOption {
+	return func(s *HelloService) {
+		s.maxTimeout = d
+	}
+}
+
+// timeoutHeader is the header a client can set instead of the timeout query parameter
+const timeoutHeader = "X-Timeout"
+
+func NewHelloService(opts ...helloOption) *HelloService {
+	s := &HelloService{clk: clock.Real, work: 10 * time.Second, mux: http.NewServeMux()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.mux.HandleFunc("/hello", s.hello)
+	s.mux.HandleFunc("/metrics", s.metrics)
+	return s
+}
+
+func (s *HelloService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(w, req)
+}
+
+// timeout is the request's deadline from ?timeout=2s or the X-Timeout header, 0 when it has none
+func (s *HelloService) timeout(req *http.Request) (time.Duration, error) {
+	v := req.URL.Query().Get("timeout")
+	if v == "" {
+		v = req.Header.Get(timeoutHeader)
+	}
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q, want a positive duration like 1.5s", v)
+	}
+	if s.maxTimeout > 0 && d > s.maxTimeout {
+		d = s.maxTimeout
+	}
+	return d, nil
+}
+
+func (s *HelloService) hello(w http.ResponseWriter, req *http.Request) {
+	s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	timeout, err := s.timeout(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A `context.Context` is created for each request by the `net/http` machinery,
+	// it is done when the client cancels the request or its connection closes.
+	work := func(ctx context.Context) (string, error) {
+		timer := s.clk.NewTimer(s.work)
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+			return "hello\n", nil
+		case <-ctx.Done():
+			return "", context.Cause(ctx)
+		}
+	}
+	var body string
+	if timeout > 0 {
+		body, err = WithTimeout(req.Context(), s.clk, timeout, work)
+	} else {
+		body, err = work(req.Context())
+	}
+
+	switch {
+	case err == nil:
+		s.completed.Add(1)
+		io.WriteString(w, body)
+	case errors.Is(err, context.DeadlineExceeded):
+		s.timedOut.Add(1)
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+	default:
+		// the client has gone away so nobody will read a response
+		s.cancelled.Add(1)
+	}
+}
+
+// metrics writes the counters in the Prometheus text format
+func (s *HelloService) metrics(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, m := range []struct {
+		name, help, typ string
+		value           int64
+	}{
+		{"hello_requests_in_flight", "Requests being worked on.", "gauge", s.inFlight.Load()},
+		{"hello_requests_completed_total", "Requests that finished their work.", "counter", s.completed.Load()},
+		{"hello_requests_cancelled_total", "Requests cancelled by the client.", "counter", s.cancelled.Load()},
+		{"hello_requests_timed_out_total", "Requests that ran past their deadline.", "counter", s.timedOut.Load()},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", m.name, m.help, m.name, m.typ, m.name, m.value)
+	}
+}
+
+func ExampleCurrency() {
+	// a port of 0 lets the system pick a free one so the lesson never clashes with another server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// the work and the deadlines run on a fake clock so the lesson only moves on when it advances the clock,
+	// on clock.Real the same requests would race 100ms of work against their deadlines
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	service := NewHelloService(helloClock(clk), helloWork(100*time.Millisecond), helloMaxTimeout(time.Second))
+	server := &http.Server{Handler: service}
+	go server.Serve(listener)
+	defer server.Close()
+	url := "http://" + listener.Addr().String()
+
+	// get sends the request and, once the handler's timers are waiting, either advances the clock by d or calls cancel
+	get := func(ctx context.Context, path string, header http.Header, timers int, d time.Duration, cancel func()) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		type response struct {
+			res *http.Response
+			err error
+		}
+		done := make(chan response, 1)
+		go func() {
+			res, err := http.DefaultClient.Do(req)
+			done <- response{res, err}
+		}()
+		if timers > 0 {
+			clk.BlockUntil(timers)
+			if cancel != nil {
+				cancel()
+			} else {
+				clk.Advance(d)
+			}
+		}
+		r := <-done
+		if r.err != nil {
+			fmt.Println(path, "client:", errors.Unwrap(r.err))
+			return
+		}
+		defer r.res.Body.Close()
+		body, _ := io.ReadAll(r.res.Body)
+		fmt.Printf("%s %d\n%s", path, r.res.StatusCode, body)
+		// a timed out handler stops its work in the background, wait for it so the next request starts with no timers
+		for clk.Waiters() > 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	get(context.Background(), "/hello", nil, 1, 100*time.Millisecond, nil)
+	get(context.Background(), "/hello?timeout=20ms", nil, 2, 20*time.Millisecond, nil)
+	get(context.Background(), "/hello", http.Header{timeoutHeader: {"soon"}}, 0, 0, nil)
+	// the client gives up halfway, the server notices through the request context
+	ctx, cancel := context.WithCancel(context.Background())
+	get(ctx, "/hello", nil, 1, 0, cancel)
+
+	// the cancellation reaches the server asynchronously, wait for the handler to count it
+	for service.completed.Load()+service.cancelled.Load()+service.timedOut.Load() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	get(context.Background(), "/metrics", nil, 0, 0, nil)
+}
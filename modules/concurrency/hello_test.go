@@ -0,0 +1,176 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"lessons/clock"
+	"lessons/leaktest"
+)
+
+type helloResponse struct {
+	status int
+	body   string
+	err    error
+}
+
+// startHello serves a HelloService on a fake clock, with 10s of work per request
+func startHello(t *testing.T, opts ...helloOption) (*HelloService, *clock.Fake, *httptest.Server) {
+	t.Helper()
+	f := clock.NewFake(epoch)
+	service := NewHelloService(append([]helloOption{helloClock(f)}, opts...)...)
+	server := httptest.NewServer(service)
+	t.Cleanup(server.Close)
+	return service, f, server
+}
+
+// request sends the request in the background, the fake clock has to be advanced before it can finish
+func request(ctx context.Context, url string, header http.Header) <-chan helloResponse {
+	done := make(chan helloResponse, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			done <- helloResponse{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		done <- helloResponse{status: res.StatusCode, body: string(body)}
+	}()
+	return done
+}
+
+// waitIdle waits for the handlers to finish, a cancelled client returns before its handler has noticed
+func waitIdle(t *testing.T, s *HelloService) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.inFlight.Load() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d requests still in flight", s.inFlight.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHelloCompletes(t *testing.T) {
+	leaktest.Check(t)
+	service, f, server := startHello(t)
+	done := request(context.Background(), server.URL+"/hello", nil)
+	f.BlockUntil(1)
+	f.Advance(10 * time.Second)
+	if r := <-done; r.status != http.StatusOK || r.body != "hello\n" {
+		t.Errorf("got %d %q, %v; want 200 hello", r.status, r.body, r.err)
+	}
+	if service.completed.Load() != 1 {
+		t.Errorf("completed = %d; want 1", service.completed.Load())
+	}
+}
+
+func TestHelloClientCancelsMidFlight(t *testing.T) {
+	leaktest.Check(t)
+	service, f, server := startHello(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := request(ctx, server.URL+"/hello", nil)
+	f.BlockUntil(1) // the handler is working
+	cancel()
+	if r := <-done; !errors.Is(r.err, context.Canceled) {
+		t.Errorf("client got %d %q, %v; want it cancelled", r.status, r.body, r.err)
+	}
+	waitIdle(t, service)
+	if service.cancelled.Load() != 1 || service.completed.Load() != 0 {
+		t.Errorf("cancelled = %d, completed = %d; want 1 and 0", service.cancelled.Load(), service.completed.Load())
+	}
+	if f.Waiters() != 0 {
+		t.Errorf("the cancelled handler left %d timers running", f.Waiters())
+	}
+}
+
+func TestHelloDeadline(t *testing.T) {
+	leaktest.Check(t)
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+		wait   time.Duration
+	}{
+		{"query", "/hello?timeout=2s", nil, 2 * time.Second},
+		{"header", "/hello", http.Header{timeoutHeader: {"3s"}}, 3 * time.Second},
+		{"capped", "/hello?timeout=1h", nil, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, f, server := startHello(t, helloMaxTimeout(5*time.Second))
+			done := request(context.Background(), server.URL+tt.path, tt.header)
+			f.BlockUntil(2) // the deadline and the work
+			f.Advance(tt.wait - time.Millisecond)
+			select {
+			case r := <-done:
+				t.Fatalf("request ended %v early: %d %q", time.Millisecond, r.status, r.body)
+			case <-time.After(10 * time.Millisecond):
+			}
+			f.Advance(time.Millisecond)
+			if r := <-done; r.status != http.StatusGatewayTimeout {
+				t.Errorf("got %d %q, %v; want 504", r.status, r.body, r.err)
+			}
+			if service.timedOut.Load() != 1 {
+				t.Errorf("timed out = %d; want 1", service.timedOut.Load())
+			}
+		})
+	}
+}
+
+func TestHelloInvalidTimeout(t *testing.T) {
+	leaktest.Check(t)
+	_, _, server := startHello(t)
+	for _, v := range []string{"soon", "-1s", "0"} {
+		r := <-request(context.Background(), server.URL+"/hello?timeout="+v, nil)
+		if r.status != http.StatusBadRequest {
+			t.Errorf("timeout=%s: got %d %q; want 400", v, r.status, r.body)
+		}
+	}
+}
+
+func TestHelloMetrics(t *testing.T) {
+	leaktest.Check(t)
+	service, f, server := startHello(t)
+
+	done := request(context.Background(), server.URL+"/hello", nil)
+	f.BlockUntil(1)
+	f.Advance(10 * time.Second)
+	<-done
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done = request(ctx, server.URL+"/hello", nil)
+	f.BlockUntil(1)
+	cancel()
+	<-done
+	waitIdle(t, service)
+
+	done = request(context.Background(), server.URL+"/hello?timeout=1s", nil)
+	f.BlockUntil(2)
+	f.Advance(time.Second)
+	<-done
+
+	r := <-request(context.Background(), server.URL+"/metrics", nil)
+	for _, want := range []string{
+		"hello_requests_in_flight 0\n",
+		"hello_requests_completed_total 1\n",
+		"hello_requests_cancelled_total 1\n",
+		"hello_requests_timed_out_total 1\n",
+		"# TYPE hello_requests_completed_total counter\n",
+	} {
+		if !strings.Contains(r.body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, r.body)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package concurrency
 
 import (
+	"context"
 	"fmt"
 	"time"
+
+	"lessons/clock"
 )
 
 /*
@@ -57,24 +60,39 @@ func ExampleSelectPattern() {
 }
 
 func ExampleSimpleTimer() {
-	timer := time.NewTimer(3 * time.Second)
+	simpleTimer(clock.Real)
+}
+
+// the time based lessons take a clock.Clock so their tests can run them on a clock.Fake instead of waiting
+func simpleTimer(clk clock.Clock) {
+	timer := clk.NewTimer(3 * time.Second)
+	stop := make(chan struct{})
 	go func() {
 		// timers setup a single delay to trigger
 		// it it accomplished ONCE in the future
-		<-timer.C
-		fmt.Println("Timer fired")
+		select {
+		case <-timer.C():
+			fmt.Println("Timer fired")
+		// Stop does not close C, without stop this goroutine would wait on it forever
+		case <-stop:
+		}
 	}()
-	time.Sleep(2 * time.Second)
-	// you can c
+	clk.Sleep(2 * time.Second)
+	// you can cancel a timer before it fires
 	stopped := timer.Stop()
+	close(stop)
 	if stopped {
 		fmt.Println("timer stopped")
 	}
 	fmt.Println("All done")
 }
 func ExampleSimpleTicker() {
+	simpleTicker(clock.Real)
+}
+
+func simpleTicker(clk clock.Clock) {
 	// tickers work on intervals
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := clk.NewTicker(500 * time.Millisecond)
 	done := make(chan bool)
 
 	go func() {
@@ -82,13 +100,13 @@ func ExampleSimpleTicker() {
 			select {
 			case <-done:
 				return
-			case tick := <-ticker.C:
+			case tick := <-ticker.C():
 				fmt.Println("Ticker fired at", tick)
 			}
 		}
 	}()
 
-	time.Sleep(1600 * time.Millisecond)
+	clk.Sleep(1600 * time.Millisecond)
 	ticker.Stop()
 	done <- true
 	fmt.Println("All done")
@@ -150,78 +168,44 @@ func runsFunc(runs int) func(ch chan int) {
 	}
 }
 
-func ExTimeOuts() {
-	// Do you need to buffer channel to prevent lockout if timedout?
-	c1 := make(chan string)
-	go func() {
-		time.Sleep(2 * time.Second)
-		c1 <- "result 1"
-		close(c1)
-	}()
-
-	go func() {
-		select {
-		case res := <-c1:
-			fmt.Println(res)
-		case <-time.After(1 * time.Second):
-			fmt.Println("timeout 1")
-		}
-	}()
-
-	done := make(chan bool)
-	c2 := make(chan string)
-	go func() {
-		time.Sleep(2 * time.Second)
-		c2 <- "result 2"
-		close(c2)
-	}()
-
-	go func() {
-		for {
-			select {
-			case res := <-c2:
-				fmt.Println(res)
-				done <- true
-			case <-time.After(3 * time.Second):
-				fmt.Println("timeout 2")
-				done <- true
-			default:
-				fmt.Println("default")
-			}
-		}
-	}()
-
-	<-done
-	close(done)
-}
-
 // Issue with multiple go routines might need to be enclosed in 1 loop
 //loop
 // go 1
 // go 2
 // are working with eachother
 
-func runner(runs int, name string) chan<- int {
+// runner sends 0 up to runs on the returned channel and closes it when done so the reader can range over it,
+// cancelling ctx stops it early when the reader gives up before the end.
+func runner(ctx context.Context, runs int, name string) <-chan int {
 	out := make(chan int)
 	go func() {
+		defer close(out)
 		for i := 0; i < runs; i++ {
-			fmt.Printf("%d runs on %s", i, name)
+			fmt.Printf("%d runs on %s\n", i, name)
+			// send picks at random when a receiver is ready and ctx is done, checking first stops a cancelled runner for sure
+			if ctx.Err() != nil || !send(ctx, out, i) {
+				return
+			}
 		}
 	}()
 	return out
 }
 
 func ExUnbufferedChan() {
+	unbufferedChan(clock.Real)
+}
+
+func unbufferedChan(clk clock.Clock) {
 	runIt := 3
 	uch := make(chan int)
 	instance := runsFunc(runIt * 3)
 	go instance(uch)
 	// intentionall delay to simulate processing
-	time.Sleep(time.Second * 1)
+	clk.Sleep(time.Second * 1)
 
 	for value := range uch {
 		fmt.Println("read ", value, "from ch")
-		time.Sleep(time.Second * 1)
+		clk.Sleep(time.Second * 1)
 	}
 
 }
@@ -244,6 +228,7 @@ func merge(left, right []int) []int {
 	return merged
 }
 
+// mergeSort starts a goroutine for every split, see Sorter in sort.go for a version that bounds them
 func mergeSort(data []int) []int {
 	if len(data) <= 1 {
 		return data
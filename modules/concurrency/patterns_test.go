@@ -0,0 +1,54 @@
+package concurrency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lessons/leaktest"
+	"lessons/registry"
+)
+
+func TestRunnerClosesChannel(t *testing.T) {
+	leaktest.Check(t)
+	var got []int
+	for i := range runner(context.Background(), 3, "test") {
+		got = append(got, i)
+	}
+	if len(got) != 3 || got[0] != 0 || got[2] != 2 {
+		t.Errorf("got %v; want [0 1 2]", got)
+	}
+}
+
+func TestRunnerStopsWhenCancelled(t *testing.T) {
+	leaktest.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	out := runner(ctx, 100, "test")
+	<-out
+	cancel()
+	// nothing reads out while runner notices, the next receive must be the close and not another value,
+	// receiving the close also waits for runner's last print
+	select {
+	case v, ok := <-out:
+		if ok {
+			t.Errorf("runner sent %d after it was cancelled", v)
+		}
+	case <-time.After(time.Second):
+		t.Error("runner did not close its channel after it was cancelled")
+	}
+}
+
+// TestLessonsDoNotLeak runs every checked lesson, the unchecked ones are run on a fake clock by clock_test.go
+func TestLessonsDoNotLeak(t *testing.T) {
+	for _, l := range registry.ByCategory("concurrency") {
+		if l.Unchecked != "" {
+			continue
+		}
+		t.Run(l.Name, func(t *testing.T) {
+			leaktest.Check(t)
+			if _, err := registry.Capture(l); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+	A pipeline is a series of stages connected by channels, each stage is a goroutine that reads from the previous stage
+	and writes to the next. fibonacciRanged is a producer stage and fibonacciSelect shows how a quit channel stops one.
+	The stages here are generic and take a context instead of a quit channel:
+	every stage closes its output when its input is closed or the context is done, so cancelling the context
+	unwinds the whole pipeline and no goroutine is left blocked on a send nobody will receive.
+*/
+
+// send delivers v on out unless ctx is done first
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// receive takes the next value from in, ok is false once in is closed or ctx is done
+// a stage must not just range over its input, an input that is never closed would keep it blocked after cancellation
+func receive[T any](ctx context.Context, in <-chan T) (v T, ok bool) {
+	select {
+	case v, ok = <-in:
+		return v, ok
+	case <-ctx.Done():
+		return v, false
+	}
+}
+
+// Generate sends the values returned by next until it returns false
+func Generate[T any](ctx context.Context, next func() (T, bool)) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := next()
+			if !ok || !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Map sends fn of every value received on in
+func Map[In, Out any](ctx context.Context, in <-chan In, fn func(In) Out) <-chan Out {
+	out := make(chan Out)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := receive(ctx, in)
+			if !ok || !send(ctx, out, fn(v)) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Filter sends the values received on in that keep returns true for
+func Filter[T any](ctx context.Context, in <-chan T, keep func(T) bool) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := receive(ctx, in)
+			if !ok || keep(v) && !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// FanOut spreads the values received on in over n channels, each value goes to whichever channel is read first
+// give every channel its own Map to process the values in parallel and FanIn to bring them back together
+func FanOut[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	outs := make([]<-chan T, n)
+	for i := range outs {
+		out := make(chan T)
+		outs[i] = out
+		go func() {
+			defer close(out)
+			for {
+				v, ok := receive(ctx, in)
+				if !ok || !send(ctx, out, v) {
+					return
+				}
+			}
+		}()
+	}
+	return outs
+}
+
+// FanIn merges the values received on all ins into one channel, it is closed once every in is closed
+func FanIn[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	for _, in := range ins {
+		wg.Add(1)
+		go func(in <-chan T) {
+			defer wg.Done()
+			for {
+				v, ok := receive(ctx, in)
+				if !ok || !send(ctx, out, v) {
+					return
+				}
+			}
+		}(in)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Batch groups the values received on in into slices of size values
+// a batch is sent early when timeout has passed since its first value so a slow input does not hold values back forever.
+// When in is closed the last, possibly smaller, batch is sent.
+func Batch[T any](ctx context.Context, in <-chan T, size int, timeout time.Duration) <-chan []T {
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+		var batch []T
+		timer := time.NewTimer(timeout)
+		stopTimer(timer)
+		defer timer.Stop()
+		flush := func() bool {
+			stopTimer(timer)
+			b := batch
+			batch = nil
+			return send(ctx, out, b)
+		}
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if len(batch) > 0 {
+						flush()
+					}
+					return
+				}
+				if len(batch) == 0 {
+					timer.Reset(timeout)
+				}
+				batch = append(batch, v)
+				if len(batch) == size && !flush() {
+					return
+				}
+			case <-timer.C:
+				if len(batch) > 0 && !flush() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// stopTimer stops t and drains a tick that already fired so a later Reset does not see it
+func stopTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+}
+
+// Tee sends every value received on in to both outputs
+// a value is only passed on once both outputs have taken it so the slower reader sets the pace
+func Tee[T any](ctx context.Context, in <-chan T) (<-chan T, <-chan T) {
+	out1, out2 := make(chan T), make(chan T)
+	go func() {
+		defer close(out1)
+		defer close(out2)
+		for {
+			v, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			// a nil channel blocks forever, so setting an output to nil once it has the value leaves the other one
+			o1, o2 := out1, out2
+			for i := 0; i < 2; i++ {
+				select {
+				case o1 <- v:
+					o1 = nil
+				case o2 <- v:
+					o2 = nil
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out1, out2
+}
+
+func ExamplePipeline() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel() // stops every stage still running when the example returns
+
+	// fibonacciRanged as a stage producing the first 15 numbers
+	x, y, n := 0, 1, 0
+	fib := Generate(ctx, func() (int, bool) {
+		v := x
+		x, y, n = y, x+y, n+1
+		return v, n <= 15
+	})
+	odd := Filter(ctx, fib, func(v int) bool { return v%2 == 1 })
+	toBatch, toSquare := Tee(ctx, odd)
+
+	var batches [][]int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for b := range Batch(ctx, toBatch, 4, time.Second) {
+			batches = append(batches, b)
+		}
+	}()
+
+	// three workers square the numbers in parallel so they come back in any order
+	var workers []<-chan int
+	for _, w := range FanOut(ctx, toSquare, 3) {
+		workers = append(workers, Map(ctx, w, func(v int) int { return v * v }))
+	}
+	var squares []int
+	for s := range FanIn(ctx, workers...) {
+		squares = append(squares, s)
+	}
+	<-done
+	sort.Ints(squares)
+	fmt.Println(squares)
+	fmt.Println(batches)
+}
@@ -0,0 +1,138 @@
+package concurrency
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"lessons/leaktest"
+)
+
+// counter generates 0, 1, 2 ... up to but not including limit, a negative limit never ends
+func counter(ctx context.Context, limit int) <-chan int {
+	n := -1
+	return Generate(ctx, func() (int, bool) {
+		n++
+		return n, limit < 0 || n < limit
+	})
+}
+
+func drain[T any](in <-chan T) []T {
+	var all []T
+	for v := range in {
+		all = append(all, v)
+	}
+	return all
+}
+
+func TestGenerateMapFilter(t *testing.T) {
+	leaktest.Check(t)
+	ctx := context.Background()
+	even := Filter(ctx, counter(ctx, 10), func(v int) bool { return v%2 == 0 })
+	got := drain(Map(ctx, even, func(v int) int { return v * 10 }))
+	if want := []int{0, 20, 40, 60, 80}; !slices.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestFanOutFanIn(t *testing.T) {
+	leaktest.Check(t)
+	ctx := context.Background()
+	outs := FanOut(ctx, counter(ctx, 100), 4)
+	if len(outs) != 4 {
+		t.Fatalf("FanOut returned %d channels; want 4", len(outs))
+	}
+	got := drain(FanIn(ctx, outs...))
+	sort.Ints(got)
+	if len(got) != 100 || got[0] != 0 || got[99] != 99 {
+		t.Errorf("FanIn received %d values; want each of 0..99 once", len(got))
+	}
+	for i := range got {
+		if got[i] != i {
+			t.Fatalf("value %d missing or repeated", i)
+		}
+	}
+}
+
+func TestBatchBySize(t *testing.T) {
+	leaktest.Check(t)
+	ctx := context.Background()
+	got := drain(Batch(ctx, counter(ctx, 7), 3, time.Hour))
+	if len(got) != 3 || !slices.Equal(got[0], []int{0, 1, 2}) || !slices.Equal(got[2], []int{6}) {
+		t.Errorf("got %v; want [[0 1 2] [3 4 5] [6]]", got)
+	}
+}
+
+func TestBatchByTimeout(t *testing.T) {
+	leaktest.Check(t)
+	ctx := context.Background()
+	in := make(chan int)
+	batches := Batch(ctx, in, 10, 10*time.Millisecond)
+	in <- 1
+	in <- 2
+	select {
+	case b := <-batches:
+		if !slices.Equal(b, []int{1, 2}) {
+			t.Errorf("got %v; want [1 2]", b)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the partial batch was not sent after the timeout")
+	}
+	close(in)
+	if rest := drain(batches); len(rest) != 0 {
+		t.Errorf("got %v after closing an empty batch; want nothing", rest)
+	}
+}
+
+func TestTee(t *testing.T) {
+	leaktest.Check(t)
+	ctx := context.Background()
+	a, b := Tee(ctx, counter(ctx, 5))
+	done := make(chan []int)
+	go func() { done <- drain(a) }()
+	gotB := drain(b)
+	gotA := <-done
+	if want := []int{0, 1, 2, 3, 4}; !slices.Equal(gotA, want) || !slices.Equal(gotB, want) {
+		t.Errorf("Tee sent %v and %v; want %v to both", gotA, gotB, want)
+	}
+}
+
+func TestCancelStopsEveryStage(t *testing.T) {
+	leaktest.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	// an endless pipeline with every stage in it, the reader stops after a few values and cancels
+	source := make(chan int) // never closed, only ctx can stop the stages reading it
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case source <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	stages := Map(ctx, Filter(ctx, FanIn(ctx, counter(ctx, -1), source), func(int) bool { return true }), func(v int) int { return v })
+	var squares []<-chan int
+	for _, w := range FanOut(ctx, stages, 3) {
+		squares = append(squares, Map(ctx, w, func(v int) int { return v * v }))
+	}
+	left, right := Tee(ctx, FanIn(ctx, squares...))
+	batches := Batch(ctx, left, 4, time.Millisecond)
+	for i := 0; i < 5; i++ {
+		<-batches
+		<-right
+	}
+	cancel()
+	// nothing reads the outputs any more, every stage has to notice ctx on its own
+}
+
+func TestCancelUnblocksSend(t *testing.T) {
+	leaktest.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	out := Map(ctx, counter(ctx, -1), func(v int) int { return v })
+	<-out
+	// the stages are now blocked sending to readers that will never come
+	cancel()
+}
@@ -0,0 +1,224 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+/*
+	ExWorkerPool shows the shape of a worker pool but throws its results away.
+	Pool is the reusable version: a fixed number of workers run fn for every submitted input,
+	every input gets exactly one Result carrying its index so the caller knows which input it belongs to,
+	a failing or panicking job only fails its own Result, and Close waits for the queued jobs before closing Results.
+*/
+
+// Result is the outcome of the job submitted with Index, 0 for the first Submit
+type Result[In, Out any] struct {
+	Index int
+	Input In
+	Value Out
+	Err   error
+}
+
+// ErrJobPanicked is the Err of a job that panicked, Value is what it panicked with
+type ErrJobPanicked struct {
+	Value any
+}
+
+func (e ErrJobPanicked) Error() string {
+	return fmt.Sprint("job panicked: ", e.Value)
+}
+
+// ErrPoolClosed is returned by Submit once Close has been called
+var ErrPoolClosed = errors.New("pool is closed")
+
+type job[In any] struct {
+	index int
+	input In
+}
+
+// Pool runs fn on a fixed number of workers
+// Results must be read while jobs are submitted, a Pool whose Results nobody reads stops accepting jobs once its workers are busy.
+type Pool[In, Out any] struct {
+	fn      func(ctx context.Context, in In) (Out, error)
+	ctx     context.Context
+	ordered bool
+
+	mu     sync.Mutex // serializes Submit so the indexes reach the workers in order
+	next   int
+	closed bool
+	jobs   chan job[In]
+
+	workers   sync.WaitGroup
+	finished  chan Result[In, Out] // written by the workers, read by collect
+	results   chan Result[In, Out]
+	collected chan struct{}
+}
+
+// NewPool starts workers goroutines, fewer than 1 is treated as 1
+// with ordered the Results come in the order the inputs were submitted, otherwise in the order they finish.
+// Once ctx is done the jobs that have not started fail with ctx.Err() and Submit stops accepting new ones.
+func NewPool[In, Out any](ctx context.Context, workers int, ordered bool, fn func(ctx context.Context, in In) (Out, error)) *Pool[In, Out] {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &Pool[In, Out]{
+		fn:        fn,
+		ctx:       ctx,
+		ordered:   ordered,
+		jobs:      make(chan job[In]),
+		finished:  make(chan Result[In, Out]),
+		results:   make(chan Result[In, Out]),
+		collected: make(chan struct{}),
+	}
+	for w := 0; w < workers; w++ {
+		p.workers.Add(1)
+		go p.work()
+	}
+	go func() {
+		p.workers.Wait()
+		close(p.finished)
+	}()
+	go p.collect()
+	return p
+}
+
+// Submit hands in to a worker, blocking until one is free
+// it returns ErrPoolClosed after Close and ctx.Err() once the pool's context is done, in both cases no Result is sent for in.
+func (p *Pool[In, Out]) Submit(in In) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case p.jobs <- job[In]{p.next, in}:
+		p.next++
+		return nil
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Results is closed once the pool is closed and every submitted job has its Result
+func (p *Pool[In, Out]) Results() <-chan Result[In, Out] {
+	return p.results
+}
+
+// Close stops accepting jobs and waits until the submitted ones have finished and their Results have been read
+// calling Close more than once is safe
+func (p *Pool[In, Out]) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+	<-p.collected
+}
+
+func (p *Pool[In, Out]) work() {
+	defer p.workers.Done()
+	for j := range p.jobs {
+		p.finished <- p.run(j)
+	}
+}
+
+// run turns a panic into an error so one bad input cannot take the whole pool down
+func (p *Pool[In, Out]) run(j job[In]) (r Result[In, Out]) {
+	r = Result[In, Out]{Index: j.index, Input: j.input}
+	if err := p.ctx.Err(); err != nil {
+		r.Err = err
+		return r
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			r.Err = ErrJobPanicked{v}
+		}
+	}()
+	r.Value, r.Err = p.fn(p.ctx, j.input)
+	return r
+}
+
+// collect forwards the finished Results, holding back the ones that finished early when the pool is ordered
+func (p *Pool[In, Out]) collect() {
+	defer close(p.collected)
+	defer close(p.results)
+	pending := make(map[int]Result[In, Out])
+	next := 0
+	for r := range p.finished {
+		if !p.ordered {
+			p.results <- r
+			continue
+		}
+		pending[r.Index] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			p.results <- r
+			next++
+		}
+	}
+}
+
+// RunPool runs fn on every input with a pool of workers and returns the Results in input order
+func RunPool[In, Out any](ctx context.Context, workers int, inputs []In, fn func(ctx context.Context, in In) (Out, error)) []Result[In, Out] {
+	p := NewPool(ctx, workers, true, fn)
+	go func() {
+		defer p.Close()
+		for _, in := range inputs {
+			if p.Submit(in) != nil {
+				return
+			}
+		}
+	}()
+	results := make([]Result[In, Out], 0, len(inputs))
+	for r := range p.Results() {
+		results = append(results, r)
+	}
+	// inputs that could not be submitted because ctx was done still get a Result
+	for i := len(results); i < len(inputs); i++ {
+		results = append(results, Result[In, Out]{Index: i, Input: inputs[i], Err: ctx.Err()})
+	}
+	return results
+}
+
+func ExamplePool() {
+	inputs := []string{"1", "2", "three", "4", "", "6"}
+	parse := func(ctx context.Context, s string) (int, error) {
+		if s == "" {
+			panic("empty input")
+		}
+		n, err := strconv.Atoi(s)
+		return n * n, err
+	}
+	for _, r := range RunPool(context.Background(), 3, inputs, parse) {
+		if r.Err != nil {
+			fmt.Println(r.Index, r.Input, "failed:", r.Err)
+			continue
+		}
+		fmt.Println(r.Index, r.Input, "squared is", r.Value)
+	}
+
+	// unordered results arrive as soon as they are ready, the index tells which input they belong to
+	p := NewPool(context.Background(), 2, false, func(ctx context.Context, n int) (int, error) {
+		return n + 1, nil
+	})
+	go func() {
+		for j := 1; j <= 5; j++ {
+			p.Submit(j)
+		}
+		p.Close()
+	}()
+	sum := 0
+	for r := range p.Results() {
+		sum += r.Value
+	}
+	fmt.Println("sum of results", sum)
+}
@@ -0,0 +1,160 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lessons/leaktest"
+)
+
+func double(ctx context.Context, n int) (int, error) {
+	return 2 * n, nil
+}
+
+func TestPoolOrdered(t *testing.T) {
+	leaktest.Check(t)
+	inputs := make([]int, 100)
+	for i := range inputs {
+		inputs[i] = i
+	}
+	// later inputs finish first so ordering has to hold them back
+	slowFirst := func(ctx context.Context, n int) (int, error) {
+		time.Sleep(time.Duration(100-n) * 10 * time.Microsecond)
+		return 2 * n, nil
+	}
+	for i, r := range RunPool(context.Background(), 8, inputs, slowFirst) {
+		if r.Index != i || r.Input != i || r.Value != 2*i || r.Err != nil {
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+}
+
+func TestPoolUnordered(t *testing.T) {
+	leaktest.Check(t)
+	p := NewPool(context.Background(), 4, false, double)
+	go func() {
+		defer p.Close()
+		for i := 0; i < 50; i++ {
+			if err := p.Submit(i); err != nil {
+				t.Errorf("Submit(%d) = %v", i, err)
+			}
+		}
+	}()
+	seen := make(map[int]bool)
+	for r := range p.Results() {
+		if r.Value != 2*r.Input || r.Index != r.Input || seen[r.Index] {
+			t.Errorf("unexpected result %+v", r)
+		}
+		seen[r.Index] = true
+	}
+	if len(seen) != 50 {
+		t.Errorf("got %d results; want 50", len(seen))
+	}
+}
+
+func TestPoolErrorsAndPanics(t *testing.T) {
+	leaktest.Check(t)
+	errOdd := errors.New("odd")
+	results := RunPool(context.Background(), 2, []int{0, 1, 2, 3}, func(ctx context.Context, n int) (int, error) {
+		switch n {
+		case 1:
+			return 0, errOdd
+		case 3:
+			panic("three")
+		}
+		return n, nil
+	})
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("successful jobs failed: %v, %v", results[0].Err, results[2].Err)
+	}
+	if !errors.Is(results[1].Err, errOdd) {
+		t.Errorf("job 1 error = %v; want %v", results[1].Err, errOdd)
+	}
+	var panicked ErrJobPanicked
+	if !errors.As(results[3].Err, &panicked) || panicked.Value != "three" {
+		t.Errorf("job 3 error = %v; want ErrJobPanicked{three}", results[3].Err)
+	}
+}
+
+func TestPoolWorkerLimit(t *testing.T) {
+	leaktest.Check(t)
+	var active, most atomic.Int32
+	inputs := make([]int, 40)
+	RunPool(context.Background(), 3, inputs, func(ctx context.Context, n int) (int, error) {
+		now := active.Add(1)
+		defer active.Add(-1)
+		for {
+			m := most.Load()
+			if now <= m || most.CompareAndSwap(m, now) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return n, nil
+	})
+	if most.Load() > 3 {
+		t.Errorf("%d jobs ran at once; want at most 3", most.Load())
+	}
+}
+
+func TestPoolCancel(t *testing.T) {
+	leaktest.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	var once sync.Once
+	inputs := make([]int, 20)
+	results := RunPool(ctx, 2, inputs, func(ctx context.Context, n int) (int, error) {
+		// the first job to run cancels everything and waits for the cancellation to arrive
+		once.Do(func() {
+			cancel()
+			close(release)
+		})
+		<-release
+		return n, nil
+	})
+	if len(results) != len(inputs) {
+		t.Fatalf("got %d results; want %d", len(results), len(inputs))
+	}
+	var cancelled int
+	for _, r := range results {
+		if errors.Is(r.Err, context.Canceled) {
+			cancelled++
+		}
+	}
+	// at most the two jobs already running when cancel was called succeed
+	if cancelled < len(inputs)-2 {
+		t.Errorf("%d results cancelled; want at least %d", cancelled, len(inputs)-2)
+	}
+}
+
+func TestPoolCloseDrains(t *testing.T) {
+	leaktest.Check(t)
+	p := NewPool(context.Background(), 2, true, func(ctx context.Context, n int) (int, error) {
+		time.Sleep(time.Millisecond)
+		return n, nil
+	})
+	var got []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for r := range p.Results() {
+			got = append(got, r.Value)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		p.Submit(i)
+	}
+	p.Close()
+	<-done
+	if len(got) != 10 {
+		t.Errorf("got %d results after Close; want 10", len(got))
+	}
+	if err := p.Submit(10); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Submit after Close = %v; want %v", err, ErrPoolClosed)
+	}
+	p.Close() // closing twice is fine
+}
@@ -0,0 +1,237 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"lessons/clock"
+)
+
+/*
+	Rate limiting decides whether an event may happen now.
+	A token bucket holds up to burst tokens and gains rate tokens every second, every event takes one,
+	so it allows short bursts while keeping the average at rate.
+	A sliding window remembers when the recent events happened and allows at most limit of them in any window,
+	it never allows a burst bigger than limit but needs memory for every event in the window.
+	Both read the time from a clock.Clock so they can be tested on a clock.Fake.
+*/
+
+// Limiter is implemented by TokenBucket and SlidingWindow, all methods are safe for concurrent use
+type Limiter interface {
+	// Allow reports whether an event may happen now and if so records it
+	Allow() bool
+	// Wait blocks until an event may happen and records it, or returns ctx.Err()
+	Wait(ctx context.Context) error
+	// Delay is how long until Allow would return true, 0 if it would now
+	Delay() time.Duration
+}
+
+// wait is Wait for any limiter, retrying after the limiter's delay
+// another goroutine can take the event first so Allow is checked again after every wait
+func wait(ctx context.Context, clk clock.Clock, l Limiter) error {
+	for !l.Allow() {
+		timer := clk.NewTimer(l.Delay())
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+type TokenBucket struct {
+	clk   clock.Clock
+	rate  float64 // tokens added per second
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time // when tokens was last brought up to date
+}
+
+// NewTokenBucket allows rate events per second on average and up to burst at once, it starts full.
+// It panics if rate is not positive or burst is less than 1, such a bucket could never allow an event.
+func NewTokenBucket(clk clock.Clock, rate float64, burst int) *TokenBucket {
+	if !(rate > 0) {
+		panic("concurrency: non-positive rate for NewTokenBucket")
+	}
+	if burst < 1 {
+		panic("concurrency: burst less than 1 for NewTokenBucket")
+	}
+	return &TokenBucket{clk: clk, rate: rate, burst: float64(burst), tokens: float64(burst), last: clk.Now()}
+}
+
+// refill adds the tokens earned since last, b.mu must be held
+func (b *TokenBucket) refill() {
+	now := b.clk.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *TokenBucket) Delay() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - b.tokens) / b.rate * float64(time.Second)))
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	return wait(ctx, b.clk, b)
+}
+
+type SlidingWindow struct {
+	clk    clock.Clock
+	limit  int
+	window time.Duration
+
+	mu     sync.Mutex
+	events []time.Time // oldest first
+}
+
+// NewSlidingWindow allows at most limit events in any period of length window.
+// It panics if limit or window is not positive.
+func NewSlidingWindow(clk clock.Clock, limit int, window time.Duration) *SlidingWindow {
+	if limit < 1 {
+		panic("concurrency: non-positive limit for NewSlidingWindow")
+	}
+	if window <= 0 {
+		panic("concurrency: non-positive window for NewSlidingWindow")
+	}
+	return &SlidingWindow{clk: clk, limit: limit, window: window}
+}
+
+// expire forgets the events that have left the window and returns now, w.mu must be held
+func (w *SlidingWindow) expire() time.Time {
+	now := w.clk.Now()
+	i := 0
+	for i < len(w.events) && !w.events[i].Add(w.window).After(now) {
+		i++
+	}
+	w.events = w.events[i:]
+	return now
+}
+
+func (w *SlidingWindow) Allow() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.expire()
+	if len(w.events) >= w.limit {
+		return false
+	}
+	w.events = append(w.events, now)
+	return true
+}
+
+func (w *SlidingWindow) Delay() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.expire()
+	if len(w.events) < w.limit {
+		return 0
+	}
+	// the oldest event that has to leave for one more to fit
+	return w.events[len(w.events)-w.limit].Add(w.window).Sub(now)
+}
+
+func (w *SlidingWindow) Wait(ctx context.Context) error {
+	return wait(ctx, w.clk, w)
+}
+
+// RateLimit answers 429 Too Many Requests when l does not allow the request
+// Retry-After tells the client how many whole seconds to wait before trying again.
+func RateLimit(l Limiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if l.Allow() {
+			next.ServeHTTP(w, req)
+			return
+		}
+		seconds := int(math.Ceil(l.Delay().Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	})
+}
+
+// Throttle passes on at most one value received on in per tick of a ticker firing every interval
+// unlike a Limiter it never drops a value, it slows the pipeline down to the ticker's pace.
+func Throttle[T any](ctx context.Context, clk clock.Clock, in <-chan T, interval time.Duration) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		ticker := clk.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			v, ok := receive(ctx, in)
+			if !ok {
+				return
+			}
+			select {
+			case <-ticker.C():
+			case <-ctx.Done():
+				return
+			}
+			if !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func ExampleRateLimit() {
+	// a fake clock makes the timeline of the lesson exact, the limiters work the same on clock.Real
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	start := clk.Now()
+	bucket := NewTokenBucket(clk, 2, 3)             // 2 per second, bursts of 3
+	window := NewSlidingWindow(clk, 3, time.Second) // 3 in any second
+	for i := 0; i < 8; i++ {
+		fmt.Printf("%v bucket %t window %t\n", clk.Now().Sub(start), bucket.Allow(), window.Allow())
+		clk.Advance(250 * time.Millisecond)
+	}
+
+	handler := RateLimit(NewTokenBucket(clk, 0.5, 1), http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "hello")
+	}))
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		fmt.Printf("%d Retry-After=%q %s\n", rec.Code, rec.Header().Get("Retry-After"), strings.TrimSpace(rec.Body.String()))
+	}
+
+	// Throttle builds on the ticker of ExampleSimpleTicker to slow a pipeline down to one value per tick
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := 0
+	slow := Throttle(ctx, clock.Real, Generate(ctx, func() (int, bool) {
+		n++
+		return n, n <= 3
+	}), 10*time.Millisecond)
+	for v := range slow {
+		fmt.Println("throttled", v)
+	}
+}
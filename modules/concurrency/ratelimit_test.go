@@ -0,0 +1,181 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"lessons/clock"
+	"lessons/leaktest"
+)
+
+func TestTokenBucket(t *testing.T) {
+	leaktest.Check(t)
+	f := clock.NewFake(epoch)
+	b := NewTokenBucket(f, 2, 3)
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("burst event %d denied", i+1)
+		}
+	}
+	if b.Allow() {
+		t.Error("event beyond the burst allowed")
+	}
+	if d := b.Delay(); d != 500*time.Millisecond {
+		t.Errorf("Delay() = %v; want 500ms for one token at 2 per second", d)
+	}
+	f.Advance(499 * time.Millisecond)
+	if b.Allow() {
+		t.Error("event allowed before the token was earned")
+	}
+	f.Advance(time.Millisecond)
+	if !b.Allow() {
+		t.Error("event denied after the token was earned")
+	}
+	// a long pause refills the bucket but never beyond burst
+	f.Advance(time.Hour)
+	allowed := 0
+	for b.Allow() {
+		allowed++
+	}
+	if allowed != 3 {
+		t.Errorf("%d events allowed after a long pause; want the burst of 3", allowed)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	leaktest.Check(t)
+	f := clock.NewFake(epoch)
+	w := NewSlidingWindow(f, 3, time.Second)
+	for i := 0; i < 3; i++ {
+		if !w.Allow() {
+			t.Fatalf("event %d denied", i+1)
+		}
+		f.Advance(100 * time.Millisecond)
+	}
+	if w.Allow() {
+		t.Error("fourth event within the window allowed")
+	}
+	// the first event was at 0s so it leaves the window at 1s
+	if d := w.Delay(); d != 700*time.Millisecond {
+		t.Errorf("Delay() = %v; want 700ms", d)
+	}
+	f.Advance(700 * time.Millisecond)
+	if !w.Allow() || w.Allow() {
+		t.Error("exactly one event should fit once the oldest has left the window")
+	}
+}
+
+func TestLimiterRejectsInvalidSettings(t *testing.T) {
+	f := clock.NewFake(epoch)
+	tests := map[string]func(){
+		"zero rate":       func() { NewTokenBucket(f, 0, 1) },
+		"negative rate":   func() { NewTokenBucket(f, -1, 1) },
+		"NaN rate":        func() { NewTokenBucket(f, math.NaN(), 1) },
+		"zero burst":      func() { NewTokenBucket(f, 1, 0) },
+		"zero limit":      func() { NewSlidingWindow(f, 0, time.Second) },
+		"negative limit":  func() { NewSlidingWindow(f, -1, time.Second) },
+		"zero window":     func() { NewSlidingWindow(f, 1, 0) },
+		"negative window": func() { NewSlidingWindow(f, 1, -time.Second) },
+	}
+	for name, newLimiter := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: the limiter was created; want a panic", name)
+				}
+			}()
+			newLimiter()
+		}()
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	leaktest.Check(t)
+	limiters := map[string]func(clk clock.Clock) Limiter{
+		"token bucket":   func(clk clock.Clock) Limiter { return NewTokenBucket(clk, 1, 1) },
+		"sliding window": func(clk clock.Clock) Limiter { return NewSlidingWindow(clk, 1, time.Second) },
+	}
+	for name, newLimiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			leaktest.Check(t)
+			f := clock.NewFake(epoch)
+			l := newLimiter(f)
+			if err := l.Wait(context.Background()); err != nil {
+				t.Fatalf("first Wait = %v", err)
+			}
+			done := make(chan error)
+			go func() { done <- l.Wait(context.Background()) }()
+			f.BlockUntil(1)
+			f.Advance(time.Second)
+			if err := <-done; err != nil {
+				t.Errorf("Wait = %v; want nil", err)
+			}
+			if got := f.Now().Sub(epoch); got != time.Second {
+				t.Errorf("Wait returned at %v; want 1s", got)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() { done <- l.Wait(ctx) }()
+			f.BlockUntil(1)
+			cancel()
+			if err := <-done; !errors.Is(err, context.Canceled) {
+				t.Errorf("Wait = %v; want %v", err, context.Canceled)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	leaktest.Check(t)
+	f := clock.NewFake(epoch)
+	handler := RateLimit(NewTokenBucket(f, 0.25, 2), http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+	for i := 0; i < 2; i++ {
+		if rec := serve(); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d got %d; want 204", i+1, rec.Code)
+		}
+	}
+	rec := serve()
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "4" {
+		t.Errorf("got %d with Retry-After %q; want 429 with Retry-After 4", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	f.Advance(3500 * time.Millisecond)
+	if rec := serve(); rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After %q with half a second to go; want it rounded up to 1", rec.Header().Get("Retry-After"))
+	}
+	f.Advance(500 * time.Millisecond)
+	if rec := serve(); rec.Code != http.StatusNoContent {
+		t.Errorf("got %d after waiting; want 204", rec.Code)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	leaktest.Check(t)
+	f := clock.NewFake(epoch)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := Throttle(ctx, f, counter(ctx, -1), time.Second)
+	for i := 0; i < 3; i++ {
+		f.BlockUntil(1)
+		select {
+		case v := <-out:
+			t.Fatalf("value %d passed before the tick", v)
+		default:
+		}
+		f.Advance(time.Second)
+		if v := <-out; v != i {
+			t.Errorf("tick %d passed %d; want %d", i+1, v, i)
+		}
+	}
+}
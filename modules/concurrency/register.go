@@ -0,0 +1,44 @@
+package concurrency
+
+import (
+	"regexp"
+
+	"lessons/registry"
+)
+
+// init registers every concurrency lesson so it can be run with "lessons run concurrency.<Name>"
+func init() {
+	const category = "concurrency"
+	for _, l := range []registry.Lesson{
+		{Name: "concurrency.ExTimeOuts", Description: "timeouts, hedged requests and retries with backoff", Run: ExTimeOuts},
+		{Name: "concurrency.ExUnbufferedChan", Description: "ranging over an unbuffered channel filled by a closure", Run: ExUnbufferedChan,
+			Unchecked: "sleeps for about ten seconds, clock_test.go runs it on a fake clock instead"},
+		{Name: "concurrency.ExWorkerPool", Description: "fixed number of workers reading from a jobs channel", Run: ExWorkerPool,
+			Volatile: []*regexp.Regexp{regexp.MustCompile(`worker \d+ working on, \d+`)}},
+		{Name: "concurrency.ExampleBufferedChan", Description: "buffered channel capacity and when sends block", Run: ExampleBufferedChan},
+		{Name: "concurrency.ExampleBufferedChanRoutine", Description: "buffered channel written by a goroutine and read with range", Run: ExampleBufferedChanRoutine,
+			Unchecked: "sleeps for about ten seconds and the written/read order varies, clock_test.go runs it on a fake clock instead"},
+		{Name: "concurrency.ExampleCurrency", Description: "HTTP service cancelling work through the request context, with deadlines and metrics", Run: ExampleCurrency},
+		{Name: "concurrency.ExampleGroup", Description: "errgroup-like Group cancelling siblings on the first error, with a limit and recovered panics", Run: ExampleGroup},
+		{Name: "concurrency.ExampleMergeSort", Description: "merge sort splitting work across goroutines", Run: ExampleMergeSort},
+		{Name: "concurrency.ExampleParallelSort", Description: "generic stable merge sort with a cutoff and a bounded number of goroutines", Run: ExampleParallelSort},
+		{Name: "concurrency.ExamplePipeline", Description: "generic context-aware pipeline stages with fan-out, fan-in, batching and tee", Run: ExamplePipeline},
+		{Name: "concurrency.ExamplePool", Description: "generic worker pool returning indexed results, errors and recovered panics", Run: ExamplePool},
+		{Name: "concurrency.ExampleRangePattern", Description: "fibonacci producer closing the channel for range", Run: ExampleRangePattern},
+		{Name: "concurrency.ExampleRateLimit", Description: "token bucket and sliding window rate limiters, a 429 middleware and a ticker throttle", Run: ExampleRateLimit},
+		{Name: "concurrency.ExampleSelectPattern", Description: "fibonacci producer stopped by a quit channel", Run: ExampleSelectPattern},
+		{Name: "concurrency.ExampleSimpleTicker", Description: "ticker firing on an interval until stopped", Run: ExampleSimpleTicker,
+			Unchecked: "sleeps for 1.6 seconds and the number of ticks depends on the load, clock_test.go runs it on a fake clock instead"},
+		{Name: "concurrency.ExampleSimpleTimer", Description: "timer stopped before it fires", Run: ExampleSimpleTimer,
+			Unchecked: "sleeps for two seconds, clock_test.go runs it on a fake clock instead"},
+		{Name: "concurrency.ExampleUnbufferedChan", Description: "unbuffered channel synchronising two goroutines", Run: ExampleUnbufferedChan,
+			Volatile: []*regexp.Regexp{regexp.MustCompile(`(?m)^(first|second)$`)}},
+		{Name: "concurrency.ExampleUnbufferedChan123", Description: "unbuffered channel closed by the sending function", Run: ExampleUnbufferedChan123,
+			Volatile: []*regexp.Regexp{regexp.MustCompile(`(?m)^(first|second)$`)}},
+		{Name: "concurrency.ExampleWGLoop", Description: "sync.WaitGroup counting items sent on a channel", Run: ExampleWGLoop,
+			Unchecked: "sleeps for about ten seconds, clock_test.go runs it on a fake clock instead"},
+	} {
+		l.Category = category
+		registry.Register(l)
+	}
+}
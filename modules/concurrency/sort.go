@@ -0,0 +1,122 @@
+package concurrency
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+/*
+	mergeSort starts a goroutine for every split right down to single elements, so sorting n items starts n goroutines
+	that each do almost nothing. Sorter keeps the idea but bounds it:
+	below the cutoff a slice is sorted sequentially because a goroutine costs more than sorting a few thousand items,
+	and a semaphore caps how many goroutines sort at the same time, when it is full the split is sorted by the current goroutine.
+*/
+
+const defaultSortCutoff = 2048
+
+// Sorter is a stable parallel merge sort for any type ordered by cmp
+// cmp returns a negative number when a sorts before b, a positive number when after and 0 when they are equal, like slices.SortFunc.
+// The merge buffer is kept between calls so sorting repeatedly does not allocate, which makes a Sorter unsafe for concurrent use.
+type Sorter[T any] struct {
+	cmp    func(a, b T) int
+	cutoff int
+	sem    chan struct{}
+	buf    []T
+}
+
+// NewSorter sorts slices shorter than cutoff sequentially and uses at most maxGoroutines goroutines, the caller's included
+// a cutoff or maxGoroutines of 0 or less picks a default, maxGoroutines defaults to GOMAXPROCS
+func NewSorter[T any](cmp func(a, b T) int, cutoff, maxGoroutines int) *Sorter[T] {
+	if cutoff <= 0 {
+		cutoff = defaultSortCutoff
+	}
+	if maxGoroutines <= 0 {
+		maxGoroutines = runtime.GOMAXPROCS(0)
+	}
+	return &Sorter[T]{cmp: cmp, cutoff: cutoff, sem: make(chan struct{}, maxGoroutines-1)}
+}
+
+// Sort sorts data in place, equal elements keep their order
+func (s *Sorter[T]) Sort(data []T) {
+	if cap(s.buf) < len(data) {
+		s.buf = make([]T, len(data))
+	}
+	s.sort(data, s.buf[:len(data)])
+}
+
+// sort sorts data using buf, which has the same length, as scratch space
+// the halves use disjoint halves of buf so they can be sorted in parallel
+func (s *Sorter[T]) sort(data, buf []T) {
+	if len(data) <= s.cutoff {
+		slices.SortStableFunc(data, s.cmp)
+		return
+	}
+	mid := len(data) / 2
+	select {
+	case s.sem <- struct{}{}:
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer func() { <-s.sem }()
+			s.sort(data[:mid], buf[:mid])
+		}()
+		s.sort(data[mid:], buf[mid:])
+		<-done
+	default:
+		// every goroutine is busy, starting another one would only queue it
+		s.sort(data[:mid], buf[:mid])
+		s.sort(data[mid:], buf[mid:])
+	}
+	s.merge(data, mid, buf)
+}
+
+// merge merges the sorted data[:mid] and data[mid:] through buf back into data
+// on a tie the left element goes first, that is what keeps the sort stable
+func (s *Sorter[T]) merge(data []T, mid int, buf []T) {
+	if s.cmp(data[mid-1], data[mid]) <= 0 {
+		return // already in order
+	}
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(data) {
+		if s.cmp(data[j], data[i]) < 0 {
+			buf[k] = data[j]
+			j++
+		} else {
+			buf[k] = data[i]
+			i++
+		}
+		k++
+	}
+	k += copy(buf[k:], data[i:mid])
+	copy(buf[k:], data[j:])
+	copy(data, buf)
+}
+
+// ParallelSort returns a sorted copy of data with the default cutoff and goroutine budget
+func ParallelSort[T any](data []T, cmp func(a, b T) int) []T {
+	sorted := slices.Clone(data)
+	NewSorter(cmp, 0, 0).Sort(sorted)
+	return sorted
+}
+
+func ExampleParallelSort() {
+	data := []int{9, 4, 3, 6, 1, 2, 10, 5, 7, 8}
+	fmt.Println(ParallelSort(data, func(a, b int) int { return a - b }))
+
+	type player struct {
+		name  string
+		score int
+	}
+	players := []player{{"ana", 3}, {"ben", 1}, {"cho", 3}, {"dev", 2}, {"eli", 1}, {"fay", 3}}
+	// a cutoff of 1 splits down to single players to show the merges keep ties in their original order
+	byScore := NewSorter(func(a, b player) int { return b.score - a.score }, 1, 4)
+	byScore.Sort(players)
+	fmt.Println(players)
+
+	// strings.Compare already has the shape of a comparator
+	words := strings.Fields("the quick brown fox jumps over the lazy dog")
+	NewSorter(strings.Compare, 0, 0).Sort(words)
+	fmt.Println(words)
+}
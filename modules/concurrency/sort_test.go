@@ -0,0 +1,136 @@
+package concurrency
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+
+	"lessons/leaktest"
+)
+
+func randomInts(n int, seed int64) []int {
+	r := rand.New(rand.NewSource(seed))
+	data := make([]int, n)
+	for i := range data {
+		data[i] = r.Intn(n)
+	}
+	return data
+}
+
+func compareInts(a, b int) int {
+	return a - b
+}
+
+func TestSorterMatchesSlicesSort(t *testing.T) {
+	leaktest.Check(t)
+	for _, n := range []int{0, 1, 2, 3, 10, 100, 1000, 10000} {
+		for _, cutoff := range []int{1, 7, 0} {
+			for _, goroutines := range []int{1, 4, 0} {
+				data := randomInts(n, int64(n))
+				want := slices.Clone(data)
+				slices.Sort(want)
+				NewSorter(compareInts, cutoff, goroutines).Sort(data)
+				if !slices.Equal(data, want) {
+					t.Errorf("n=%d cutoff=%d goroutines=%d: not sorted", n, cutoff, goroutines)
+				}
+			}
+		}
+	}
+}
+
+func TestParallelSortLeavesInputAlone(t *testing.T) {
+	leaktest.Check(t)
+	data := []int{3, 1, 2}
+	sorted := ParallelSort(data, compareInts)
+	if !slices.Equal(sorted, []int{1, 2, 3}) || !slices.Equal(data, []int{3, 1, 2}) {
+		t.Errorf("ParallelSort = %v, input now %v; want [1 2 3], input [3 1 2]", sorted, data)
+	}
+}
+
+func TestSorterStable(t *testing.T) {
+	leaktest.Check(t)
+	type record struct {
+		key, pos int
+	}
+	r := rand.New(rand.NewSource(1))
+	data := make([]record, 5000)
+	for i := range data {
+		data[i] = record{key: r.Intn(10), pos: i} // lots of equal keys
+	}
+	for _, cutoff := range []int{1, 16, 0} {
+		sorted := slices.Clone(data)
+		NewSorter(func(a, b record) int { return a.key - b.key }, cutoff, 4).Sort(sorted)
+		for i := 1; i < len(sorted); i++ {
+			a, b := sorted[i-1], sorted[i]
+			if a.key > b.key || (a.key == b.key && a.pos > b.pos) {
+				t.Fatalf("cutoff=%d: %v sorted before %v", cutoff, a, b)
+			}
+		}
+	}
+}
+
+func TestSorterReusesBuffer(t *testing.T) {
+	leaktest.Check(t)
+	// a single goroutine so the only allocation Sort could make is the buffer
+	s := NewSorter(compareInts, 4, 1)
+	s.Sort(randomInts(1000, 1))
+	small := randomInts(100, 2)
+	work := make([]int, len(small))
+	allocs := testing.AllocsPerRun(10, func() {
+		copy(work, small)
+		s.Sort(work)
+	})
+	if allocs != 0 || cap(s.buf) != 1000 {
+		t.Errorf("Sort allocated %v times per run, buffer capacity %d; want 0 and the 1000 of the first sort", allocs, cap(s.buf))
+	}
+}
+
+const benchSize = 100000
+
+func BenchmarkSortSlice(b *testing.B) {
+	data := randomInts(benchSize, 1)
+	work := make([]int, len(data))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(work, data)
+		sort.Slice(work, func(i, j int) bool { return work[i] < work[j] })
+	}
+}
+
+func BenchmarkSortStableSlicesPackage(b *testing.B) {
+	data := randomInts(benchSize, 1)
+	work := make([]int, len(data))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(work, data)
+		slices.SortStableFunc(work, compareInts)
+	}
+}
+
+func BenchmarkParallelSort(b *testing.B) {
+	data := randomInts(benchSize, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParallelSort(data, compareInts)
+	}
+}
+
+func BenchmarkSorterInPlace(b *testing.B) {
+	data := randomInts(benchSize, 1)
+	work := make([]int, len(data))
+	s := NewSorter(compareInts, 0, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(work, data)
+		s.Sort(work)
+	}
+}
+
+func BenchmarkMergeSort(b *testing.B) {
+	data := randomInts(benchSize, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mergeSort(data)
+	}
+}
@@ -0,0 +1,231 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"lessons/clock"
+)
+
+/*
+	Timeouts, hedged requests and retries all come down to a select between the work and a timer.
+	The work runs in its own goroutine and reports on a channel buffered for every result,
+	so when the timer wins the goroutine can still finish and exit instead of blocking forever on a send nobody reads.
+	The timer comes from a clock.Clock so tests can use a clock.Fake and never actually wait.
+*/
+
+type result[T any] struct {
+	value T
+	err   error
+}
+
+// WithTimeout returns what fn returns unless it takes longer than d
+// on timeout fn's context is cancelled with context.DeadlineExceeded as its cause and the error wraps context.DeadlineExceeded.
+func WithTimeout[T any](ctx context.Context, clk clock.Clock, d time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	done := make(chan result[T], 1)
+	go func() {
+		v, err := fn(ctx)
+		done <- result[T]{v, err}
+	}()
+	timer := clk.NewTimer(d)
+	defer timer.Stop()
+
+	var zero T
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C():
+		cancel(context.DeadlineExceeded)
+		return zero, fmt.Errorf("timed out after %v: %w", d, context.DeadlineExceeded)
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// FirstOf is a hedged request, it returns the first successful result of fns
+// fns[0] starts straight away and every hedge without a result starts the next one, a failure starts the next one immediately.
+// A hedge of 0 or less starts them all at once. The others are cancelled once one succeeds,
+// when every one of them fails the errors are joined.
+func FirstOf[T any](ctx context.Context, clk clock.Clock, hedge time.Duration, fns ...func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if len(fns) == 0 {
+		return zero, errors.New("FirstOf: no functions to call")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan result[T], len(fns))
+	started := 0
+	start := func() {
+		fn := fns[started]
+		started++
+		go func() {
+			v, err := fn(ctx)
+			results <- result[T]{v, err}
+		}()
+	}
+
+	start()
+	var timer clock.Timer
+	var hedgeC <-chan time.Time // nil, and so never selected, once everything has started
+	if hedge <= 0 {
+		for started < len(fns) {
+			start()
+		}
+	} else if started < len(fns) {
+		timer = clk.NewTimer(hedge)
+		defer timer.Stop()
+		hedgeC = timer.C()
+	}
+	// startNext starts the next function and re-arms the timer for the one after it
+	startNext := func() {
+		start()
+		if started == len(fns) {
+			hedgeC = nil
+			timer.Stop()
+			return
+		}
+		timer.Reset(hedge)
+	}
+
+	var errs []error
+	for {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				return r.value, nil
+			}
+			errs = append(errs, r.err)
+			if len(errs) == len(fns) {
+				return zero, errors.Join(errs...)
+			}
+			if started < len(fns) {
+				startNext()
+			}
+		case <-hedgeC:
+			startNext()
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Backoff configures RetryWithBackoff
+// the n-th wait is Initial * Multiplier^(n-1), capped at Max, and Jitter takes up to that fraction off it at random
+// so clients that failed together do not retry together.
+type Backoff struct {
+	Initial     time.Duration
+	Max         time.Duration  // 0 is no cap
+	Multiplier  float64        // less than 1 is treated as 2
+	Jitter      float64        // between 0 and 1
+	MaxElapsed  time.Duration  // give up instead of waiting past this much time since the first attempt, 0 is no limit
+	MaxAttempts int            // 0 is no limit
+	Rand        func() float64 // returns a number in [0, 1) for the jitter, nil uses math/rand
+}
+
+// ErrRetriesExhausted is wrapped by the error RetryWithBackoff gives up with once MaxAttempts or MaxElapsed is reached,
+// the same error also wraps the last attempt's error so errors.Is finds either
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+// delay is the wait after the given failed attempt, counting from 1
+func (b Backoff) delay(attempt int) time.Duration {
+	m := b.Multiplier
+	if m < 1 {
+		m = 2
+	}
+	d := float64(b.Initial)
+	for i := 1; i < attempt && (b.Max <= 0 || d < float64(b.Max)); i++ {
+		d *= m
+	}
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		random := b.Rand
+		if random == nil {
+			random = rand.Float64
+		}
+		d -= d * b.Jitter * random()
+	}
+	return time.Duration(d)
+}
+
+// RetryWithBackoff calls fn until it succeeds, waiting longer after every failure
+// it gives up with an error wrapping ErrRetriesExhausted and fn's last error when MaxAttempts or MaxElapsed is reached,
+// or with ctx.Err() when ctx is done. Without either limit only ctx stops it.
+func RetryWithBackoff[T any](ctx context.Context, clk clock.Clock, b Backoff, fn func(ctx context.Context) (T, error)) (T, error) {
+	start := clk.Now()
+	for attempt := 1; ; attempt++ {
+		v, err := fn(ctx)
+		if err == nil {
+			return v, nil
+		}
+		var zero T
+		if b.MaxAttempts > 0 && attempt >= b.MaxAttempts {
+			return zero, fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempt, err)
+		}
+		delay := b.delay(attempt)
+		if b.MaxElapsed > 0 && clk.Now().Add(delay).Sub(start) > b.MaxElapsed {
+			return zero, fmt.Errorf("%w after %d attempts in %v: %w", ErrRetriesExhausted, attempt, clk.Now().Sub(start), err)
+		}
+		timer := clk.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// slowly returns value after d unless ctx is cancelled first
+func slowly(clk clock.Clock, d time.Duration, value string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		timer := clk.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+			return value, nil
+		case <-ctx.Done():
+			return "", context.Cause(ctx)
+		}
+	}
+}
+
+func ExTimeOuts() {
+	// the first result takes longer than its timeout, the second one makes it
+	// unlike selecting on time.After in a loop nothing spins on a default case and nothing is left blocked on c1 or c2
+	ctx := context.Background()
+	clk := clock.Real
+	if _, err := WithTimeout(ctx, clk, 10*time.Millisecond, slowly(clk, 50*time.Millisecond, "result 1")); err != nil {
+		fmt.Println("timeout 1:", err)
+	}
+	if res, err := WithTimeout(ctx, clk, 100*time.Millisecond, slowly(clk, 10*time.Millisecond, "result 2")); err == nil {
+		fmt.Println(res)
+	}
+
+	// the primary replica is slow so after 20ms the request is hedged to the secondary which answers first
+	res, err := FirstOf(ctx, clk, 20*time.Millisecond,
+		slowly(clk, time.Second, "primary"),
+		slowly(clk, 10*time.Millisecond, "secondary"))
+	fmt.Println("first of:", res, err)
+
+	attempts := 0
+	flaky := func(ctx context.Context) (int, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, fmt.Errorf("attempt %d failed", attempts)
+		}
+		return attempts, nil
+	}
+	n, err := RetryWithBackoff(ctx, clk, Backoff{Initial: time.Millisecond, Max: 10 * time.Millisecond, Jitter: 0.5}, flaky)
+	fmt.Println("succeeded on attempt", n, err)
+
+	attempts = -10
+	_, err = RetryWithBackoff(ctx, clk, Backoff{Initial: time.Millisecond, MaxAttempts: 4}, flaky)
+	fmt.Println(err)
+}
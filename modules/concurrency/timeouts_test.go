@@ -0,0 +1,232 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lessons/clock"
+	"lessons/leaktest"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// blockUntilCancelled is work that never finishes on its own
+func blockUntilCancelled(ctx context.Context) (string, error) {
+	<-ctx.Done()
+	return "", context.Cause(ctx)
+}
+
+func TestWithTimeoutReturnsResult(t *testing.T) {
+	leaktest.Check(t)
+	errBoom := errors.New("boom")
+	got, err := WithTimeout(context.Background(), clock.NewFake(epoch), time.Second, func(context.Context) (int, error) {
+		return 7, errBoom
+	})
+	if got != 7 || !errors.Is(err, errBoom) {
+		t.Errorf("WithTimeout = %d, %v; want 7, %v", got, err, errBoom)
+	}
+}
+
+func TestWithTimeoutExpires(t *testing.T) {
+	leaktest.Check(t)
+	f := clock.NewFake(epoch)
+	cause := make(chan error, 1)
+	done := make(chan error)
+	go func() {
+		_, err := WithTimeout(context.Background(), f, time.Minute, func(ctx context.Context) (string, error) {
+			_, err := blockUntilCancelled(ctx)
+			cause <- err
+			return "", err
+		})
+		done <- err
+	}()
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WithTimeout error = %v; want %v", err, context.DeadlineExceeded)
+	}
+	if err := <-cause; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("fn saw cause %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWithTimeoutParentCancelled(t *testing.T) {
+	leaktest.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := WithTimeout(ctx, clock.NewFake(epoch), time.Minute, blockUntilCancelled)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WithTimeout error = %v; want %v", err, context.Canceled)
+	}
+}
+
+func TestFirstOfHedges(t *testing.T) {
+	leaktest.Check(t)
+	f := clock.NewFake(epoch)
+	var secondaryStarted atomic.Bool
+	primaryDone := make(chan error, 1)
+	done := make(chan string)
+	go func() {
+		res, _ := FirstOf(context.Background(), f, time.Second,
+			func(ctx context.Context) (string, error) {
+				_, err := blockUntilCancelled(ctx)
+				primaryDone <- err
+				return "", err
+			},
+			func(ctx context.Context) (string, error) {
+				secondaryStarted.Store(true)
+				return "secondary", nil
+			})
+		done <- res
+	}()
+	f.BlockUntil(1)
+	if secondaryStarted.Load() {
+		t.Fatal("secondary started before the hedge delay")
+	}
+	f.Advance(time.Second)
+	if res := <-done; res != "secondary" {
+		t.Errorf("FirstOf = %q; want secondary", res)
+	}
+	if err := <-primaryDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("primary ended with %v; want it cancelled", err)
+	}
+}
+
+func TestFirstOfFailureStartsNextImmediately(t *testing.T) {
+	leaktest.Check(t)
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	fail := func(err error) func(context.Context) (int, error) {
+		return func(context.Context) (int, error) { return 0, err }
+	}
+	// the fake clock never moves so only the failures can start the next function
+	_, err := FirstOf(context.Background(), clock.NewFake(epoch), time.Hour, fail(errA), fail(errB))
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("FirstOf error = %v; want both errors joined", err)
+	}
+
+	got, err := FirstOf(context.Background(), clock.NewFake(epoch), time.Hour, fail(errA), func(context.Context) (int, error) { return 2, nil })
+	if got != 2 || err != nil {
+		t.Errorf("FirstOf = %d, %v; want 2, nil", got, err)
+	}
+}
+
+func TestFirstOfWithoutHedge(t *testing.T) {
+	leaktest.Check(t)
+	got, err := FirstOf(context.Background(), clock.NewFake(epoch), 0, blockUntilCancelled, func(context.Context) (string, error) {
+		return "fast", nil
+	})
+	if got != "fast" || err != nil {
+		t.Errorf("FirstOf = %q, %v; want fast, nil", got, err)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	leaktest.Check(t)
+	tests := []struct {
+		b       Backoff
+		attempt int
+		want    time.Duration
+	}{
+		{Backoff{Initial: time.Second}, 1, time.Second},
+		{Backoff{Initial: time.Second}, 4, 8 * time.Second},
+		{Backoff{Initial: time.Second, Multiplier: 3}, 3, 9 * time.Second},
+		{Backoff{Initial: time.Second, Max: 5 * time.Second}, 10, 5 * time.Second},
+		{Backoff{Initial: time.Second, Max: 5 * time.Second}, 1000, 5 * time.Second},
+		{Backoff{Initial: time.Second, Jitter: 0.2, Rand: func() float64 { return 0.5 }}, 1, 900 * time.Millisecond},
+		{Backoff{Initial: time.Second, Jitter: 1, Rand: func() float64 { return 0 }}, 2, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.b.delay(tt.attempt); got != tt.want {
+			t.Errorf("%+v delay(%d) = %v; want %v", tt.b, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// retryOnFake runs RetryWithBackoff on f in a goroutine, advancing the clock by each of waits when it is asked to wait
+func retryOnFake(f *clock.Fake, b Backoff, fn func(context.Context) (int, error), waits ...time.Duration) (int, error) {
+	type outcome struct {
+		n   int
+		err error
+	}
+	done := make(chan outcome)
+	go func() {
+		n, err := RetryWithBackoff(context.Background(), f, b, fn)
+		done <- outcome{n, err}
+	}()
+	for _, d := range waits {
+		f.BlockUntil(1)
+		f.Advance(d)
+	}
+	o := <-done
+	return o.n, o.err
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	leaktest.Check(t)
+	f := clock.NewFake(epoch)
+	var attempts []time.Duration
+	fn := func(context.Context) (int, error) {
+		attempts = append(attempts, f.Now().Sub(epoch))
+		if len(attempts) < 5 {
+			return 0, errors.New("not yet")
+		}
+		return len(attempts), nil
+	}
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second}
+	n, err := retryOnFake(f, b, fn, time.Second, 2*time.Second, 4*time.Second, 5*time.Second)
+	if n != 5 || err != nil {
+		t.Fatalf("RetryWithBackoff = %d, %v; want 5, nil", n, err)
+	}
+	want := []time.Duration{0, time.Second, 3 * time.Second, 7 * time.Second, 12 * time.Second}
+	for i := range want {
+		if attempts[i] != want[i] {
+			t.Errorf("attempt %d at %v; want %v", i+1, attempts[i], want[i])
+		}
+	}
+}
+
+func TestRetryWithBackoffGivesUp(t *testing.T) {
+	leaktest.Check(t)
+	errDown := errors.New("service down")
+	var calls int
+	fn := func(context.Context) (int, error) {
+		calls++
+		return 0, errDown
+	}
+
+	// attempts at 0s, 1s and 3s, the next one would be at 7s which is past MaxElapsed
+	_, err := retryOnFake(clock.NewFake(epoch), Backoff{Initial: time.Second, MaxElapsed: 5 * time.Second}, fn, time.Second, 2*time.Second)
+	if calls != 3 || !errors.Is(err, ErrRetriesExhausted) || !errors.Is(err, errDown) {
+		t.Errorf("MaxElapsed: %d calls, error %v; want 3 calls and ErrRetriesExhausted wrapping %v", calls, err, errDown)
+	}
+
+	calls = 0
+	_, err = retryOnFake(clock.NewFake(epoch), Backoff{Initial: time.Second, MaxAttempts: 2}, fn, time.Second)
+	if calls != 2 || !errors.Is(err, ErrRetriesExhausted) {
+		t.Errorf("MaxAttempts: %d calls, error %v; want 2 calls and ErrRetriesExhausted", calls, err)
+	}
+}
+
+func TestRetryWithBackoffCancelled(t *testing.T) {
+	leaktest.Check(t)
+	f := clock.NewFake(epoch)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := RetryWithBackoff(ctx, f, Backoff{Initial: time.Hour}, func(context.Context) (int, error) {
+			return 0, errors.New("fail")
+		})
+		done <- err
+	}()
+	f.BlockUntil(1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("RetryWithBackoff error = %v; want %v", err, context.Canceled)
+	}
+	if f.Waiters() != 0 {
+		t.Errorf("%d timers left waiting after cancellation", f.Waiters())
+	}
+}
@@ -0,0 +1,24 @@
+package conversions
+
+import (
+	"regexp"
+
+	"lessons/registry"
+)
+
+// init registers every conversions lesson so it can be run with "lessons run conversions.<Name>"
+func init() {
+	const category = "conversions"
+	for _, l := range []registry.Lesson{
+		{Name: "conversions.ExampleAliasing", Description: "type aliases compared to defined types", Run: ExampleAliasing},
+		{Name: "conversions.ExampleConversionCosts", Description: "run-time cost of converting numbers into strings", Run: ExampleConversionCosts,
+			Volatile: []*regexp.Regexp{registry.VolatileDuration}},
+		{Name: "conversions.ExampleConversions", Description: "conversions between types sharing an underlying type", Run: ExampleConversions},
+		{Name: "conversions.ExampleNumericConversions", Description: "run-time cost of converting between ints and floats", Run: ExampleNumericConversions,
+			Volatile: []*regexp.Regexp{registry.VolatileDuration}},
+		{Name: "conversions.ExamplePointerConversion", Description: "explicit and indirect pointer type conversions", Run: ExamplePointerConversion},
+	} {
+		l.Category = category
+		registry.Register(l)
+	}
+}
@@ -0,0 +1,15 @@
+package defering
+
+import "lessons/registry"
+
+// init registers every defering lesson so it can be run with "lessons run defering.<Name>"
+func init() {
+	const category = "defering"
+	for _, l := range []registry.Lesson{
+		{Name: "defering.ExampleDeferTracing", Description: "order in which deferred arguments and calls are evaluated", Run: ExampleDeferTracing},
+		{Name: "defering.ExampleDefering", Description: "deferring the teardown closure returned by a setup function", Run: ExampleDefering},
+	} {
+		l.Category = category
+		registry.Register(l)
+	}
+}
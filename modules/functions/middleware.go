@@ -2,7 +2,8 @@ package functions
 
 import (
 	"fmt"
-	"time"
+
+	"lessons/clock"
 )
 
 // closures can be used to:
@@ -12,11 +13,12 @@ import (
 // -implement sort.Search as a binary search (algorithm used to search a sorted list) using closures
 //      O(1,000,000) = log10(1,000,000)/log10(2) = 19.93 maximum searchs to find an item within 1,000,000 sorted list
 // -defer promised work via goroutine and anonymous function. This is not very relevant but you can read about it here https://www.calhoun.io/5-useful-ways-to-use-closures-in-go/
-func timingPtr(f func(*[]int) *[]int) func(*[]int) *[]int {
+// the timing middlewares read the time from clk so a test can pass a clock.Fake and get the same duration every run
+func timingPtr(clk clock.Clock, f func(*[]int) *[]int) func(*[]int) *[]int {
 	return func(si *[]int) *[]int {
-		start := time.Now()
+		start := clk.Now()
 		sliced := f(si)
-		end := time.Now()
+		end := clk.Now()
 		fmt.Println("ptr process time took", end.Sub(start))
 		return sliced
 	}
@@ -25,21 +27,21 @@ func timingPtr(f func(*[]int) *[]int) func(*[]int) *[]int {
 // Slices are sliceheaders which have a pointer to the location
 // timingPtr and timingCopy are the same because the pointer is being accessed
 // and slices are references to that pointer
-func timingCopy(f func([]int) []int) func([]int) []int {
+func timingCopy(clk clock.Clock, f func([]int) []int) func([]int) []int {
 	return func(si []int) []int {
-		start := time.Now()
+		start := clk.Now()
 		sliced := f(si)
-		end := time.Now()
+		end := clk.Now()
 		fmt.Println("copy process time took", end.Sub(start))
 		return sliced
 	}
 }
 
-func timingBool(f func([]int) []int) func([]int) bool {
+func timingBool(clk clock.Clock, f func([]int) []int) func([]int) bool {
 	return func(si []int) bool {
-		start := time.Now()
+		start := clk.Now()
 		sliced := f(si)
-		end := time.Now()
+		end := clk.Now()
 		fmt.Println("copy process time took", end.Sub(start), " for ", sliced)
 		return 0 < len(sliced)
 	}
@@ -72,10 +74,10 @@ func ExampleMiddleware() {
 	// 	}
 	// }(p)
 
-	d1 := timingCopy(processSlicerCopy)
+	d1 := timingCopy(clock.Real, processSlicerCopy)
 	slicedCopy := d1(p)
 
-	d2 := timingPtr(processSlicerPtr)
+	d2 := timingPtr(clock.Real, processSlicerPtr)
 	slicedPtr := d2(&p)
 
 	fmt.Println("timed with copy", slicedCopy)
@@ -84,7 +86,7 @@ func ExampleMiddleware() {
 
 func ExampleMiddleBool() {
 	p := []int{2, 3, 5, 8, 13, 26}
-	sl := timingBool(processSlicerCopy)
+	sl := timingBool(clock.Real, processSlicerCopy)
 	if sl(p) {
 		fmt.Println("Timing finished")
 	}
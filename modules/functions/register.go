@@ -0,0 +1,32 @@
+package functions
+
+import (
+	"regexp"
+
+	"lessons/registry"
+)
+
+// init registers every functions lesson so it can be run with "lessons run functions.<Name>"
+func init() {
+	const category = "functions"
+	for _, l := range []registry.Lesson{
+		{Name: "functions.ExampleAnonymous", Description: "anonymous functions assigned and invoked immediately", Run: ExampleAnonymous},
+		{Name: "functions.ExampleByValueAndReference", Description: "passing values, pointers and maps to functions", Run: ExampleByValueAndReference,
+			Volatile: []*regexp.Regexp{registry.VolatileAddress}},
+		{Name: "functions.ExampleFilterSlice", Description: "filtering a slice with a predicate function", Run: ExampleFilterSlice},
+		{Name: "functions.ExampleFuncReturns", Description: "function types and functions returning functions", Run: ExampleFuncReturns},
+		{Name: "functions.ExampleMiddleBool", Description: "timing middleware returning a bool", Run: ExampleMiddleBool,
+			Volatile: []*regexp.Regexp{registry.VolatileDuration}},
+		{Name: "functions.ExampleMiddleware", Description: "timing middleware wrapping slice processors", Run: ExampleMiddleware,
+			Volatile: []*regexp.Regexp{registry.VolatileDuration}},
+		{Name: "functions.ExampleProcessSlice", Description: "passing a function as an argument to process a slice", Run: ExampleProcessSlice},
+		{Name: "functions.ExampleSearchInt", Description: "closure around sort.Search", Run: ExampleSearchInt},
+		{Name: "functions.ExampleSliceExpand", Description: "growing a slice by hand and watching its capacity", Run: ExampleSliceExpand,
+			Volatile: []*regexp.Regexp{registry.VolatileAddress}},
+		{Name: "functions.ExampleVariadic", Description: "variadic parameters and expanding a slice into them", Run: ExampleVariadic},
+		{Name: "functions.SortingSlice", Description: "custom ordering by implementing sort.Interface", Run: SortingSlice},
+	} {
+		l.Category = category
+		registry.Register(l)
+	}
+}
@@ -53,7 +53,6 @@ func (bp byPriority) Less(i, j int) bool {
 	for i:=0; i<len(fixed)-1; i++{
       reversed[i] = fixed[len(fixed)-i]
 	}
-*/
 
         //fmt.Println("row", row)
         lastVal := row[1]
@@ -88,4 +87,5 @@ func (bp byPriority) Less(i, j int) bool {
             break
         }
     }
-    return int64(max)
\ No newline at end of file
+    return int64(max)
+*/
@@ -26,7 +26,7 @@ type ForError struct {
 func (fr *ForError) doSomething() (*ForError, error) {
 	fmt.Println("executing panic")
 	panic(fr.e)
-	return &ForError{"This never returns"}, nil // this never returns because everything is reset in the recovery logic
+	// return &ForError{"This never returns"}, nil // this never returns because everything is reset in the recovery logic
 }
 
 func perform() (fr *ForError, err error) {
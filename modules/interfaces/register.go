@@ -0,0 +1,18 @@
+package interfaces
+
+import "lessons/registry"
+
+// init registers every interfaces lesson so it can be run with "lessons run interfaces.<Name>"
+func init() {
+	const category = "interfaces"
+	for _, l := range []registry.Lesson{
+		{Name: "interfaces.ExampleAssertion", Description: "type assertions against empty and method interfaces", Run: ExampleAssertion},
+		{Name: "interfaces.ExampleErrorPanicRecovery", Description: "recovering a panic into a custom error type", Run: ExampleErrorPanicRecovery},
+		{Name: "interfaces.ExampleInterface", Description: "satisfying the Processor interface with different types", Run: ExampleInterface},
+		{Name: "interfaces.ExampleRot13", Description: "rot13 by wrapping an io.Reader", Run: ExampleRot13},
+		{Name: "interfaces.ExampleRot13NoStruct", Description: "rot13 without a wrapping struct", Run: ExampleRot13NoStruct},
+	} {
+		l.Category = category
+		registry.Register(l)
+	}
+}
@@ -0,0 +1,15 @@
+package loops
+
+import "lessons/registry"
+
+// init registers every loops lesson so it can be run with "lessons run loops.<Name>"
+func init() {
+	const category = "loops"
+	for _, l := range []registry.Lesson{
+		{Name: "loops.ExampleLoops", Description: "closures capturing the loop variable", Run: ExampleLoops},
+		{Name: "loops.ExampleLoopsPassedByValue", Description: "loop variable passed by value and by reference", Run: ExampleLoopsPassedByValue},
+	} {
+		l.Category = category
+		registry.Register(l)
+	}
+}
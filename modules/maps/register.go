@@ -0,0 +1,13 @@
+package maps
+
+import "lessons/registry"
+
+// init registers every maps lesson so it can be run with "lessons run maps.<Name>"
+func init() {
+	registry.Register(registry.Lesson{
+		Name:        "maps.ExampleMaps",
+		Category:    "maps",
+		Description: "maps are references so copies share their data",
+		Run:         ExampleMaps,
+	})
+}
@@ -0,0 +1,20 @@
+package structs
+
+import "lessons/registry"
+
+// init registers every structs lesson so it can be run with "lessons run structs.<Name>"
+func init() {
+	const category = "structs"
+	for _, l := range []registry.Lesson{
+		{Name: "structs.ExampleHighOrderFunc", Description: "higher-order functions built from struct fields", Run: ExampleHighOrderFunc},
+		{Name: "structs.ExampleImplementation", Description: "zero values and embedded structs", Run: ExampleImplementation},
+		{Name: "structs.ExamplePointerAndValue", Description: "pointer receivers while ranging over a slice", Run: ExamplePointerAndValue},
+		{Name: "structs.ExamplePointerVsValue", Description: "pointer receivers compared to value receivers", Run: ExamplePointerVsValue},
+		{Name: "structs.ExampleSizeOfStruct", Description: "field ordering and struct alignment", Run: ExampleSizeOfStruct},
+		{Name: "structs.ExampleStructImplementations", Description: "anonymous struct types", Run: ExampleStructImplementations},
+		{Name: "structs.MethodMath", Description: "methods with value and pointer receivers", Run: MethodMath},
+	} {
+		l.Category = category
+		registry.Register(l)
+	}
+}
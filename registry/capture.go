@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Capture runs the lesson with os.Stdout redirected into a buffer and returns everything it printed.
+// Lessons print with fmt.Println so swapping os.Stdout is enough, no lesson needs to accept an io.Writer.
+// A panicking lesson is recovered and reported as an error along with whatever it printed before the panic.
+// Capture is not safe to call concurrently because os.Stdout is a package variable.
+func Capture(l Lesson) (out string, err error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	// the pipe has to be drained while the lesson runs, otherwise a lesson
+	// that prints more than the pipe buffer would block forever
+	type result struct {
+		out []byte
+		err error
+	}
+	read := make(chan result, 1)
+	go func() {
+		var b bytes.Buffer
+		_, err := io.Copy(&b, r)
+		read <- result{b.Bytes(), err}
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	func() {
+		defer func() {
+			os.Stdout = stdout
+			w.Close()
+			if e := recover(); e != nil {
+				err = fmt.Errorf("registry: lesson %s panicked: %v", l.Name, e)
+			}
+		}()
+		l.Run()
+	}()
+
+	res := <-read
+	if err == nil {
+		err = res.err
+	}
+	return string(res.out), err
+}
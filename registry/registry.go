@@ -0,0 +1,129 @@
+// Package registry keeps track of every lesson that can be executed from the lessons command.
+// Module packages register their Example functions from an init() function, the same side effect
+// import pattern explained in modules/stdimports, so main only needs to blank import a module to run its lessons.
+package registry
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// Lesson describes a single runnable lesson.
+// Name is the qualified name used on the command line e.g. algorithms.ExampleQueue
+type Lesson struct {
+	Name        string
+	Category    string
+	Description string
+	Run         func()
+
+	// Volatile matches output that changes between runs, e.g. timings, addresses or random numbers.
+	// Every match is replaced by VolatileMask before the output is compared with its golden file.
+	Volatile []*regexp.Regexp
+	// Unchecked explains why the output cannot be compared at all, e.g. the lesson blocks forever
+	// or its goroutines print in a different order every run. Unchecked lessons are not run by the golden test.
+	Unchecked string
+}
+
+// VolatileMask replaces every Volatile match in captured output.
+const VolatileMask = "<volatile>"
+
+// Volatile patterns shared by many lessons.
+var (
+	// VolatileAddress matches pointers printed with %p or &value
+	VolatileAddress = regexp.MustCompile(`0x[0-9a-f]+`)
+	// VolatileDuration matches a time.Duration printed with fmt e.g. 1.5ms or 1m2.3s
+	VolatileDuration = regexp.MustCompile(`(?:\d+(?:\.\d+)?(?:ns|µs|ms|h|m|s))+`)
+)
+
+// Mask replaces the Volatile sections of out so two runs of the lesson produce the same text.
+func (l Lesson) Mask(out string) string {
+	for _, re := range l.Volatile {
+		out = re.ReplaceAllString(out, VolatileMask)
+	}
+	return out
+}
+
+var (
+	mu      sync.RWMutex
+	lessons = make(map[string]Lesson)
+)
+
+// Register makes a lesson available by its name.
+// Like database/sql.Register it panics if Register is called twice with the same name or if Run is nil,
+// because both are programmer errors that should be found the first time the binary starts.
+func Register(l Lesson) {
+	mu.Lock()
+	defer mu.Unlock()
+	if l.Run == nil {
+		panic("registry: Register lesson " + l.Name + " has a nil Run")
+	}
+	if _, dup := lessons[l.Name]; dup {
+		panic("registry: Register called twice for lesson " + l.Name)
+	}
+	lessons[l.Name] = l
+}
+
+// Lessons returns every registered lesson sorted by name.
+func Lessons() []Lesson {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]Lesson, 0, len(lessons))
+	for _, l := range lessons {
+		list = append(list, l)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Lookup returns the lesson registered under name.
+func Lookup(name string) (Lesson, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	l, ok := lessons[name]
+	return l, ok
+}
+
+// Match returns the lessons whose name matches the glob pattern, see path.Match for the syntax.
+// e.g. "concurrency.Ex*" or "*.ExampleQueue"
+func Match(pattern string) ([]Lesson, error) {
+	// validate the pattern once so a bad pattern is reported even when nothing is registered
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("registry: bad pattern %q: %w", pattern, err)
+	}
+	var matched []Lesson
+	for _, l := range Lessons() {
+		if ok, _ := path.Match(pattern, l.Name); ok {
+			matched = append(matched, l)
+		}
+	}
+	return matched, nil
+}
+
+// ByCategory returns the lessons registered under category sorted by name.
+func ByCategory(category string) []Lesson {
+	var matched []Lesson
+	for _, l := range Lessons() {
+		if l.Category == category {
+			matched = append(matched, l)
+		}
+	}
+	return matched
+}
+
+// Categories returns the sorted, de-duplicated list of categories.
+func Categories() []string {
+	seen := make(map[string]struct{}) // map[keyType]struct{} acts as a set, see types.ExampleSmallestType
+	var categories []string
+	for _, l := range Lessons() {
+		if _, ok := seen[l.Category]; ok {
+			continue
+		}
+		seen[l.Category] = struct{}{}
+		categories = append(categories, l.Category)
+	}
+	sort.Strings(categories)
+	return categories
+}
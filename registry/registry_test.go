@@ -0,0 +1,60 @@
+package registry
+
+import "testing"
+
+// register adds a lesson for the duration of the test so go test -count=2 can register it again
+func register(t *testing.T, l Lesson) {
+	t.Helper()
+	Register(l)
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(lessons, l.Name)
+	})
+}
+
+func TestMatch(t *testing.T) {
+	noop := func() {}
+	register(t, Lesson{Name: "registrytest.ExampleOne", Category: "registrytest", Run: noop})
+	register(t, Lesson{Name: "registrytest.ExampleTwo", Category: "registrytest", Run: noop})
+	register(t, Lesson{Name: "registrytest.Other", Category: "registrytest", Run: noop})
+
+	var tests = []struct {
+		pattern string
+		want    int
+	}{
+		{"registrytest.ExampleOne", 1},
+		{"registrytest.Example*", 2},
+		{"registrytest.*", 3},
+		{"*.Other", 1},
+		{"nothing.*", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := Match(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("Match(%q) found %d lessons, want %d", tt.pattern, len(got), tt.want)
+			}
+		})
+	}
+
+	if _, err := Match("["); err == nil {
+		t.Error("Match(\"[\") should report a bad pattern")
+	}
+	if got := len(ByCategory("registrytest")); got != 3 {
+		t.Errorf("ByCategory found %d lessons, want 3", got)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	register(t, Lesson{Name: "registrytest.Duplicate", Run: func() {}})
+	defer func() {
+		if recover() == nil {
+			t.Error("registering the same name twice should panic")
+		}
+	}()
+	Register(Lesson{Name: "registrytest.Duplicate", Run: func() {}})
+}
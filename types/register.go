@@ -0,0 +1,39 @@
+package types
+
+import (
+	"regexp"
+
+	"lessons/registry"
+)
+
+// init registers every types lesson so it can be run with "lessons run types.<Name>"
+func init() {
+	const category = "types"
+	for _, l := range []registry.Lesson{
+		{Name: "types.ExampleArrays", Description: "arrays are values and are copied", Run: ExampleArrays},
+		{Name: "types.ExampleByteSlice", Description: "byte slices, strings and runes", Run: ExampleByteSlice},
+		{Name: "types.ExampleBytes", Description: "implementing io.Writer on a byte slice", Run: ExampleBytes},
+		{Name: "types.ExampleConst", Description: "typed and untyped constants", Run: ExampleConst},
+		{Name: "types.ExampleEmbed", Description: "embedding pointer structs", Run: ExampleEmbed,
+			Unchecked: "panics because the embedded *Embedded2 is never allocated"},
+		{Name: "types.ExampleMap", Description: "map literals and lookups", Run: ExampleMap},
+		{Name: "types.ExampleMinInts", Description: "minimum of a variadic list of ints", Run: ExampleMinInts},
+		{Name: "types.ExamplePrinting", Description: "fmt verbs for printing values", Run: ExamplePrinting},
+		{Name: "types.ExampleSlice", Description: "slicing and modifying a slice through a function", Run: ExampleSlice},
+		{Name: "types.ExampleSliceAddress", Description: "addresses of slice elements and range copies", Run: ExampleSliceAddress,
+			Volatile: []*regexp.Regexp{registry.VolatileAddress}},
+		{Name: "types.ExampleSliceAddressPtrs", Description: "addresses of a slice of pointers", Run: ExampleSliceAddressPtrs,
+			Volatile: []*regexp.Regexp{registry.VolatileAddress}},
+		{Name: "types.ExampleSlices", Description: "slice literals with indexed elements", Run: ExampleSlices},
+		{Name: "types.ExampleSlicing", Description: "re-slicing while ranging", Run: ExampleSlicing},
+		{Name: "types.ExampleSmallestType", Description: "the zero size empty struct", Run: ExampleSmallestType,
+			// map iteration order is random
+			Volatile: []*regexp.Regexp{regexp.MustCompile(`smallmap key \w+`)}},
+		{Name: "types.ExampleStringConcat", Description: "concatenating with strings.Builder", Run: ExampleStringConcat},
+		{Name: "types.TypeInformation", Description: "integer limits and bitwise even/odd checks", Run: TypeInformation,
+			Volatile: []*regexp.Regexp{regexp.MustCompile(`\d+ is (odd|even)`)}},
+	} {
+		l.Category = category
+		registry.Register(l)
+	}
+}